@@ -0,0 +1,226 @@
+package gosuilending
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/shopspring/decimal"
+)
+
+type (
+	// Amount is a raw token amount with its decimals, e.g. Raw 150000000 with Decimals 8 is 1.5
+	Amount struct {
+		Raw      *big.Int
+		Decimals int32
+	}
+
+	// PoolDecimals keep the coin decimals of every dola pool
+	PoolDecimals struct {
+		lock     sync.RWMutex
+		decimals map[uint16]int32
+	}
+)
+
+func NewAmount(raw *big.Int, decimals int32) Amount {
+	return Amount{Raw: raw, Decimals: decimals}
+}
+
+// NewDolaAmount wrap an amount or usd value returned by the contract, which are all 1e8
+func NewDolaAmount(raw *big.Int) Amount {
+	return NewAmount(raw, dolaDecimal)
+}
+
+// ParseAmount parse a human readable amount like "1.5", more fraction digits than decimals
+// and negative amounts are errors
+func ParseAmount(str string, decimals int32) (Amount, error) {
+	d, err := decimal.NewFromString(str)
+	if err != nil {
+		return Amount{}, fmt.Errorf("invalid amount %q: %w", str, err)
+	}
+	if d.Sign() < 0 {
+		return Amount{}, fmt.Errorf("negative amount %q", str)
+	}
+	if d.Exponent() < -decimals {
+		return Amount{}, fmt.Errorf("amount %q has more than %d decimals", str, decimals)
+	}
+	return Amount{Raw: d.Shift(decimals).BigInt(), Decimals: decimals}, nil
+}
+
+func (a Amount) Decimal() decimal.Decimal {
+	if a.Raw == nil {
+		return decimal.Zero
+	}
+	return decimal.NewFromBigInt(a.Raw, -a.Decimals)
+}
+
+// String render the amount as a decimal, e.g. 1.5
+func (a Amount) String() string {
+	return a.Decimal().String()
+}
+
+// RawString is the raw amount, as SupplyArgs.DepositAmount and others take
+func (a Amount) RawString() string {
+	if a.Raw == nil {
+		return "0"
+	}
+	return a.Raw.String()
+}
+
+func (a Amount) Uint64() (uint64, error) {
+	if a.Raw == nil || a.Raw.Sign() < 0 || !a.Raw.IsUint64() {
+		return 0, fmt.Errorf("amount %s out of u64", a.RawString())
+	}
+	return a.Raw.Uint64(), nil
+}
+
+func (a Amount) U256() (*big.Int, error) {
+	if a.Raw == nil || a.Raw.Sign() < 0 || a.Raw.Cmp(maxU256) > 0 {
+		return nil, fmt.Errorf("amount %s out of u256", a.RawString())
+	}
+	return new(big.Int).Set(a.Raw), nil
+}
+
+// Rescale convert to decimals, extra digits are truncated
+func (a Amount) Rescale(decimals int32) Amount {
+	raw := new(big.Int)
+	if a.Raw != nil {
+		raw.Set(a.Raw)
+	}
+	switch {
+	case decimals > a.Decimals:
+		raw.Mul(raw, pow10(int(decimals-a.Decimals)))
+	case decimals < a.Decimals:
+		raw.Quo(raw, pow10(int(a.Decimals-decimals)))
+	}
+	return Amount{Raw: raw, Decimals: decimals}
+}
+
+// DolaAmount convert to the 1e8 amount used by the contract
+func (a Amount) DolaAmount() Amount {
+	return a.Rescale(dolaDecimal)
+}
+
+// USDValue is amount * price
+func (a Amount) USDValue(price DolaTokenPrice) decimal.Decimal {
+	return a.Decimal().Mul(price.USD())
+}
+
+// USD is the price as a decimal, Price / 10^Decimal
+func (p DolaTokenPrice) USD() decimal.Decimal {
+	if p.Price == nil {
+		return decimal.Zero
+	}
+	return decimal.NewFromBigInt(p.Price, -int32(p.Decimal))
+}
+
+// Amount is the 1e8 collateral amount
+func (i CollateralItem) Amount() Amount {
+	return NewDolaAmount(i.CollateralAmount)
+}
+
+func (i CollateralItem) ValueUSD() decimal.Decimal {
+	return NewDolaAmount(i.CollateralValue).Decimal()
+}
+
+// Amount is the 1e8 debt amount
+func (i DebtItem) Amount() Amount {
+	return NewDolaAmount(i.DebtAmount)
+}
+
+func (i DebtItem) ValueUSD() decimal.Decimal {
+	return NewDolaAmount(i.DebtValue).Decimal()
+}
+
+func (u UserLendingInfo) CollateralUSD() decimal.Decimal {
+	return NewDolaAmount(u.TotalCollateralValue).Decimal()
+}
+
+func (u UserLendingInfo) DebtUSD() decimal.Decimal {
+	return NewDolaAmount(u.TotalDebtValue).Decimal()
+}
+
+// HealthFactorDecimal render the 1e27 health factor, e.g. 1.25
+func (u UserLendingInfo) HealthFactorDecimal() decimal.Decimal {
+	if u.HealthFactor == nil {
+		return decimal.Zero
+	}
+	return decimal.NewFromBigInt(u.HealthFactor, -27)
+}
+
+func NewPoolDecimals(decimals map[uint16]int32) *PoolDecimals {
+	d := &PoolDecimals{decimals: make(map[uint16]int32, len(decimals))}
+	for id, v := range decimals {
+		d.decimals[id] = v
+	}
+	return d
+}
+
+func (d *PoolDecimals) Set(dolaPoolId uint16, decimals int32) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	d.decimals[dolaPoolId] = decimals
+}
+
+func (d *PoolDecimals) Decimals(dolaPoolId uint16) (int32, error) {
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+	decimals, ok := d.decimals[dolaPoolId]
+	if !ok {
+		return 0, fmt.Errorf("decimals of pool %d unknown", dolaPoolId)
+	}
+	return decimals, nil
+}
+
+// Parse parse a human readable amount of the pool coin, e.g. "1.5" USDT
+func (d *PoolDecimals) Parse(dolaPoolId uint16, str string) (Amount, error) {
+	decimals, err := d.Decimals(dolaPoolId)
+	if err != nil {
+		return Amount{}, err
+	}
+	return ParseAmount(str, decimals)
+}
+
+// FromDola convert a 1e8 amount returned by the contract to the pool coin decimals
+func (d *PoolDecimals) FromDola(dolaPoolId uint16, raw *big.Int) (Amount, error) {
+	decimals, err := d.Decimals(dolaPoolId)
+	if err != nil {
+		return Amount{}, err
+	}
+	return NewDolaAmount(raw).Rescale(decimals), nil
+}
+
+// LoadPoolDecimals read the coin metadata of the sui pool of every dola pool
+func (q *QueryClient) LoadPoolDecimals(ctx context.Context, dolaPoolIds ...uint16) (*PoolDecimals, error) {
+	if len(dolaPoolIds) == 0 {
+		return nil, errors.New("no dola pool id")
+	}
+	pools := make([][]PoolInfo, len(dolaPoolIds))
+	batch := q.NewBatch()
+	for i, id := range dolaPoolIds {
+		batch.GetAllPoolLiquidity(id, &pools[i])
+	}
+	if err := batch.Execute(ctx); err != nil {
+		return nil, err
+	}
+	result := NewPoolDecimals(nil)
+	for i, id := range dolaPoolIds {
+		coinType := ""
+		for _, pool := range pools[i] {
+			if pool.DolaChainId == DolaChainIdSui {
+				coinType = pool.DolaAddress
+			}
+		}
+		if coinType == "" {
+			return nil, fmt.Errorf("dola pool %d has no sui pool", id)
+		}
+		metadata, err := q.contract.client.GetCoinMetadata(ctx, coinType)
+		if err != nil {
+			return nil, fmt.Errorf("get %s metadata: %w", coinType, err)
+		}
+		result.Set(id, int32(metadata.Decimals))
+	}
+	return result, nil
+}
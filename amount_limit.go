@@ -0,0 +1,131 @@
+package gosuilending
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+)
+
+const (
+	LimitByHealth     LimitConstraint = "health"     // health factor would fall below 1
+	LimitByCollateral LimitConstraint = "collateral" // all the collateral of the pool is withdrawn
+	LimitByLiquidity  LimitConstraint = "liquidity"  // the pool on the dst chain has no more token
+)
+
+type (
+	LimitConstraint string
+
+	// AmountLimit is the max amount of a withdraw or borrow and the constraint binding it,
+	// amounts are 100000000 -> 100000000/1e8 = 1
+	AmountLimit struct {
+		Amount          *big.Int
+		Binding         LimitConstraint
+		HealthLimit     *big.Int
+		CollateralLimit *big.Int // nil for borrow
+		LiquidityLimit  *big.Int // liquidity of the pool on the dst chain
+	}
+)
+
+// MaxWithdraw return the max amount of dolaPoolId the user can withdraw to dstChainId
+func (q *QueryClient) MaxWithdraw(ctx context.Context, dolaUserId string, dolaPoolId uint16, dstChainId uint16) (*AmountLimit, error) {
+	engine, lendingInfo, liquidity, err := q.limitInputs(ctx, dolaUserId, dolaPoolId, dstChainId)
+	if err != nil {
+		return nil, err
+	}
+	return withdrawLimit(engine, lendingInfo, dolaPoolId, liquidity)
+}
+
+// MaxBorrow return the max amount of dolaPoolId the user can borrow to dstChainId
+func (q *QueryClient) MaxBorrow(ctx context.Context, dolaUserId string, dolaPoolId uint16, dstChainId uint16) (*AmountLimit, error) {
+	engine, lendingInfo, liquidity, err := q.limitInputs(ctx, dolaUserId, dolaPoolId, dstChainId)
+	if err != nil {
+		return nil, err
+	}
+	return borrowLimit(engine, lendingInfo, dolaPoolId, liquidity)
+}
+
+// MaxWithdraw is QueryClient.MaxWithdraw
+func (c *Contract) MaxWithdraw(ctx context.Context, dolaUserId string, dolaPoolId uint16, dstChainId uint16) (*AmountLimit, error) {
+	return c.QueryClient().MaxWithdraw(ctx, dolaUserId, dolaPoolId, dstChainId)
+}
+
+// MaxBorrow is QueryClient.MaxBorrow
+func (c *Contract) MaxBorrow(ctx context.Context, dolaUserId string, dolaPoolId uint16, dstChainId uint16) (*AmountLimit, error) {
+	return c.QueryClient().MaxBorrow(ctx, dolaUserId, dolaPoolId, dstChainId)
+}
+
+func withdrawLimit(engine *RiskEngine, lendingInfo *UserLendingInfo, dolaPoolId uint16, liquidity *big.Int) (*AmountLimit, error) {
+	var err error
+	limit := &AmountLimit{CollateralLimit: big.NewInt(0), LiquidityLimit: liquidity}
+	if collateral := findCollateral(lendingInfo, dolaPoolId); collateral != nil {
+		limit.CollateralLimit = collateral.CollateralAmount
+	}
+	// MaxWithdraw is capped by the collateral, below it only the health is binding
+	if limit.HealthLimit, err = engine.MaxWithdraw(lendingInfo, dolaPoolId); err != nil {
+		return nil, err
+	}
+	limit.Amount, limit.Binding = limit.HealthLimit, LimitByHealth
+	if limit.HealthLimit.Cmp(limit.CollateralLimit) >= 0 {
+		limit.Binding = LimitByCollateral
+	}
+	limit.bindLiquidity()
+	return limit, nil
+}
+
+func borrowLimit(engine *RiskEngine, lendingInfo *UserLendingInfo, dolaPoolId uint16, liquidity *big.Int) (*AmountLimit, error) {
+	var err error
+	limit := &AmountLimit{LiquidityLimit: liquidity}
+	if limit.HealthLimit, err = engine.AllowedBorrow(lendingInfo, dolaPoolId); err != nil {
+		return nil, err
+	}
+	limit.Amount, limit.Binding = limit.HealthLimit, LimitByHealth
+	limit.bindLiquidity()
+	return limit, nil
+}
+
+func (l *AmountLimit) bindLiquidity() {
+	if l.LiquidityLimit.Cmp(l.Amount) < 0 {
+		l.Amount, l.Binding = l.LiquidityLimit, LimitByLiquidity
+	}
+	l.Amount = new(big.Int).Set(l.Amount)
+}
+
+// limitInputs query the lending info, reserves, prices and pool liquidity at once
+func (q *QueryClient) limitInputs(ctx context.Context, dolaUserId string, dolaPoolId uint16, dstChainId uint16) (*RiskEngine, *UserLendingInfo, *big.Int, error) {
+	var (
+		lendingInfo *UserLendingInfo
+		reserves    []ReserveInfo
+		prices      []DolaTokenPrice
+		pools       []PoolInfo
+	)
+	err := q.NewBatch().
+		GetUserLendingInfo(dolaUserId, &lendingInfo).
+		GetAllReserveInfo(&reserves).
+		GetAllOraclePrice(&prices).
+		GetAllPoolLiquidity(dolaPoolId, &pools).
+		Execute(ctx)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	liquidity, err := chainLiquidity(pools, dolaPoolId, dstChainId)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return NewRiskEngine(reserves, prices), lendingInfo, liquidity, nil
+}
+
+// chainLiquidity sum the liquidity of the pools of dstChainId
+func chainLiquidity(pools []PoolInfo, dolaPoolId uint16, dstChainId uint16) (*big.Int, error) {
+	liquidity := big.NewInt(0)
+	found := false
+	for _, pool := range pools {
+		if pool.DolaChainId == dstChainId && pool.PoolLiquidity != nil {
+			liquidity.Add(liquidity, pool.PoolLiquidity)
+			found = true
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("dola pool %d has no pool on chain %d", dolaPoolId, dstChainId)
+	}
+	return liquidity, nil
+}
@@ -0,0 +1,50 @@
+package gosuilending
+
+import (
+	"math/big"
+	"testing"
+)
+
+func Test_amountLimit(t *testing.T) {
+	engine := NewRiskEngine([]ReserveInfo{
+		{DolaPoolId: 0, CollateralCoefficient: new(big.Int).Quo(new(big.Int).Mul(rayUnit, big.NewInt(8)), big.NewInt(10)), BorrowCoefficient: rayUnit},
+		{DolaPoolId: 1, CollateralCoefficient: rayUnit, BorrowCoefficient: rayUnit},
+	}, []DolaTokenPrice{
+		{DolaPoolId: 0, Decimal: 2, Price: big.NewInt(3000000)}, // 30000
+		{DolaPoolId: 1, Decimal: 2, Price: big.NewInt(100)},     // 1
+	})
+	lendingInfo := &UserLendingInfo{
+		CollateralInfos: []CollateralItem{{DolaPoolId: 0, CollateralAmount: big.NewInt(1e8)}},
+		DebtInfos:       []DebtItem{{DolaPoolId: 1, DebtAmount: big.NewInt(12000e8)}},
+	}
+
+	limit, err := withdrawLimit(engine, lendingInfo, 0, big.NewInt(1e9))
+	AssertNil(err)
+	if limit.Binding != LimitByHealth || limit.Amount.Cmp(big.NewInt(5e7)) != 0 {
+		t.Errorf("withdrawLimit() = %+v", limit)
+	}
+	limit, err = withdrawLimit(engine, lendingInfo, 0, big.NewInt(1e7))
+	AssertNil(err)
+	if limit.Binding != LimitByLiquidity || limit.Amount.Cmp(big.NewInt(1e7)) != 0 {
+		t.Errorf("withdrawLimit() = %+v", limit)
+	}
+	limit, err = withdrawLimit(engine, &UserLendingInfo{CollateralInfos: lendingInfo.CollateralInfos}, 0, big.NewInt(1e9))
+	AssertNil(err)
+	if limit.Binding != LimitByCollateral || limit.Amount.Cmp(big.NewInt(1e8)) != 0 {
+		t.Errorf("withdrawLimit() without debt = %+v", limit)
+	}
+
+	limit, err = borrowLimit(engine, lendingInfo, 1, big.NewInt(5000e8))
+	AssertNil(err)
+	if limit.Binding != LimitByLiquidity || limit.HealthLimit.Cmp(big.NewInt(12000e8)) != 0 || limit.Amount.Cmp(big.NewInt(5000e8)) != 0 {
+		t.Errorf("borrowLimit() = %+v", limit)
+	}
+
+	pools := []PoolInfo{{DolaChainId: 0, PoolLiquidity: big.NewInt(1)}, {DolaChainId: 5, PoolLiquidity: big.NewInt(2)}}
+	if liquidity, err := chainLiquidity(pools, 1, 5); err != nil || liquidity.Int64() != 2 {
+		t.Errorf("chainLiquidity() = %v, %v", liquidity, err)
+	}
+	if _, err = chainLiquidity(pools, 1, 4); err == nil {
+		t.Errorf("chainLiquidity() should fail without pool on the chain")
+	}
+}
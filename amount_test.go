@@ -0,0 +1,44 @@
+package gosuilending
+
+import (
+	"math/big"
+	"testing"
+)
+
+func Test_ParseAmount(t *testing.T) {
+	amount, err := ParseAmount("1.5", 6)
+	AssertNil(err)
+	if amount.RawString() != "1500000" || amount.String() != "1.5" || amount.DolaAmount().RawString() != "150000000" {
+		t.Errorf("ParseAmount() = %s/%s", amount.RawString(), amount)
+	}
+	raw, err := amount.Uint64()
+	AssertNil(err)
+	if raw != 1500000 {
+		t.Errorf("Uint64() = %d", raw)
+	}
+
+	for _, str := range []string{"1.0000001", "-1", "abc"} {
+		if _, err = ParseAmount(str, 6); err == nil {
+			t.Errorf("ParseAmount(%q) should fail", str)
+		}
+	}
+
+	// 1e8 amount to 6 decimals is truncated
+	decimals := NewPoolDecimals(map[uint16]int32{1: 6})
+	amount, err = decimals.FromDola(1, big.NewInt(123456789))
+	AssertNil(err)
+	if amount.String() != "1.234567" {
+		t.Errorf("FromDola() = %s", amount)
+	}
+	if _, err = decimals.Parse(2, "1"); err == nil {
+		t.Errorf("Parse() should fail on unknown pool")
+	}
+
+	price := DolaTokenPrice{DolaPoolId: 1, Decimal: 2, Price: big.NewInt(3000000)}
+	if value := amount.USDValue(price); value.String() != "37037.01" {
+		t.Errorf("USDValue() = %s", value)
+	}
+	if _, err = NewAmount(new(big.Int).Lsh(big.NewInt(1), 256), 0).U256(); err == nil {
+		t.Errorf("U256() should fail on 2^256")
+	}
+}
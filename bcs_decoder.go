@@ -0,0 +1,154 @@
+package gosuilending
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"reflect"
+	"strings"
+
+	"github.com/coming-chat/go-sui/v2/lib"
+	"github.com/coming-chat/go-sui/v2/types"
+	"github.com/fardream/go-bcs/bcs"
+)
+
+var bigIntType = reflect.TypeOf((*big.Int)(nil))
+
+// unmarshalMoveBcs decode the bcs of a move struct into v field by field. Fields are read in
+// declaration order and named by the `move` tag, the tag option u128/u256 give the width of a
+// *big.Int field. Errors name the field failed, e.g. ReserveInfo.pools[1].pool_weight.
+//
+// go-bcs Decoder is not used for values, it can't decode integers and slices of structs
+func unmarshalMoveBcs(data []byte, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return errors.New("decode target must be a non nil pointer")
+	}
+	name := rv.Elem().Type().Name()
+	r := bytes.NewReader(data)
+	if err := decodeMoveValue(r, rv.Elem(), name, ""); err != nil {
+		return err
+	}
+	if r.Len() != 0 {
+		return fmt.Errorf("decode %s: %d bytes left", name, r.Len())
+	}
+	return nil
+}
+
+// unmarshalEventBcs decode the base58 bcs of event into v
+func unmarshalEventBcs(event types.SuiEvent, v any) error {
+	if event.Bcs == "" {
+		return errors.New("event without bcs")
+	}
+	data, err := lib.NewBase58(event.Bcs)
+	if err != nil {
+		return fmt.Errorf("decode event bcs: %w", err)
+	}
+	return unmarshalMoveBcs(data.Data(), v)
+}
+
+func decodeMoveValue(r *bytes.Reader, v reflect.Value, path string, width string) error {
+	if v.Type() == bigIntType {
+		size := 32
+		if width == "u128" {
+			size = 16
+		}
+		buf := make([]byte, size)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return fmt.Errorf("decode %s: %w", path, err)
+		}
+		// little endian to big endian
+		for i, j := 0, len(buf)-1; i < j; i, j = i+1, j-1 {
+			buf[i], buf[j] = buf[j], buf[i]
+		}
+		v.Set(reflect.ValueOf(new(big.Int).SetBytes(buf)))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		b, err := r.ReadByte()
+		if err != nil {
+			return fmt.Errorf("decode %s: %w", path, err)
+		}
+		if b > 1 {
+			return fmt.Errorf("decode %s: invalid bool %d", path, b)
+		}
+		v.SetBool(b == 1)
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		buf := make([]byte, 8)
+		if _, err := io.ReadFull(r, buf[:v.Type().Size()]); err != nil {
+			return fmt.Errorf("decode %s: %w", path, err)
+		}
+		v.SetUint(binary.LittleEndian.Uint64(buf))
+	case reflect.String:
+		data, err := readMoveBytes(r)
+		if err != nil {
+			return fmt.Errorf("decode %s: %w", path, err)
+		}
+		v.SetString(string(data))
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if err := decodeMoveValue(r, v.Index(i), fmt.Sprintf("%s[%d]", path, i), width); err != nil {
+				return err
+			}
+		}
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			data, err := readMoveBytes(r)
+			if err != nil {
+				return fmt.Errorf("decode %s: %w", path, err)
+			}
+			v.SetBytes(data)
+			return nil
+		}
+		size, _, err := bcs.ULEB128Decode[int](r)
+		if err != nil {
+			return fmt.Errorf("decode %s length: %w", path, err)
+		}
+		if size > r.Len() {
+			return fmt.Errorf("decode %s: length %d exceeds data", path, size)
+		}
+		slice := reflect.MakeSlice(v.Type(), size, size)
+		for i := 0; i < size; i++ {
+			if err := decodeMoveValue(r, slice.Index(i), fmt.Sprintf("%s[%d]", path, i), width); err != nil {
+				return err
+			}
+		}
+		v.Set(slice)
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < v.NumField(); i++ {
+			field := t.Field(i)
+			name, option, _ := strings.Cut(field.Tag.Get("move"), ",")
+			if name == "-" || !field.IsExported() {
+				continue
+			}
+			if name == "" {
+				name = field.Name
+			}
+			if err := decodeMoveValue(r, v.Field(i), path+"."+name, option); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("decode %s: unsupported kind %s", path, v.Kind())
+	}
+	return nil
+}
+
+func readMoveBytes(r *bytes.Reader) ([]byte, error) {
+	size, _, err := bcs.ULEB128Decode[int](r)
+	if err != nil {
+		return nil, err
+	}
+	if size > r.Len() {
+		return nil, fmt.Errorf("length %d exceeds data", size)
+	}
+	data := make([]byte, size)
+	_, err = io.ReadFull(r, data)
+	return data, err
+}
@@ -0,0 +1,117 @@
+package gosuilending
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/coming-chat/go-sui/v2/lib"
+	"github.com/coming-chat/go-sui/v2/types"
+)
+
+func Test_unmarshalMoveBcs(t *testing.T) {
+	var buf bytes.Buffer
+	le := func(v any) { AssertNil(binary.Write(&buf, binary.LittleEndian, v)) }
+	le(uint16(3)) // dola_pool_id
+	buf.WriteByte(1)
+	le(uint16(5)) // pools[0].pool_address.dola_chain_id
+	buf.Write([]byte{2, 0xab, 0xcd})
+	for _, v := range []uint64{1000, 2, 3} { // pool_liquidity, pool_equilibrium_fee, pool_weight
+		le(v)
+		buf.Write(make([]byte, 24))
+	}
+	for i := uint64(1); i <= 7; i++ { // coefficients, apys, reserve, debt and utilization_rate
+		le(i * 100)
+		buf.Write(make([]byte, 24))
+	}
+
+	var layout moveReserveInfo
+	AssertNil(unmarshalMoveBcs(buf.Bytes(), &layout))
+	info := layout.reserveInfo()
+	if info.DolaPoolId != 3 || len(info.Pools) != 1 || info.Pools[0].DolaAddress != "0xabcd" || info.Pools[0].PoolLiquidity.Cmp(big.NewInt(1000)) != 0 {
+		t.Errorf("unexpected reserve info %+v", info)
+	}
+	if info.UtilizationRate != 700 || info.Debt.Cmp(big.NewInt(600)) != 0 {
+		t.Errorf("unexpected reserve info %+v", info)
+	}
+
+	err := unmarshalMoveBcs(buf.Bytes()[:20], &layout)
+	if err == nil || !strings.Contains(err.Error(), "moveReserveInfo.pools[0].pool_liquidity") {
+		t.Errorf("error should name the field, got %v", err)
+	}
+}
+
+func Test_DecodeLendingPortalEvent(t *testing.T) {
+	var buf bytes.Buffer
+	le := func(v any) { AssertNil(binary.Write(&buf, binary.LittleEndian, v)) }
+	le(uint64(9)) // nonce
+	sender := make([]byte, 32)
+	sender[31] = 1
+	buf.Write(sender)
+	buf.Write([]byte{1, 0xaa}) // dola_pool_address
+	le(uint16(0))              // source_chain_id
+	le(uint16(5))              // dst_chain_id
+	buf.Write([]byte{2, 1, 2}) // receiver
+	le(uint64(100))
+	buf.WriteByte(CallTypeWithdraw)
+
+	event, err := DecodeLendingPortalEvent(types.SuiEvent{Bcs: lib.Base58(buf.Bytes()).String()})
+	AssertNil(err)
+	if event.Nonce != 9 || event.DstChainId != 5 || event.Amount != 100 || event.CallType != CallTypeWithdraw || !bytes.Equal(event.Receiver, []byte{1, 2}) {
+		t.Errorf("unexpected event %+v", event)
+	}
+	if !strings.HasSuffix(event.Sender, "01") {
+		t.Errorf("unexpected sender %s", event.Sender)
+	}
+}
+
+func Test_PoolInfo_bcsAndJson(t *testing.T) {
+	var buf bytes.Buffer
+	le := func(v any) { AssertNil(binary.Write(&buf, binary.LittleEndian, v)) }
+	le(uint16(DolaChainIdSui)) // pool_address.dola_chain_id
+	buf.Write([]byte{2, 0xab, 0xcd})
+	for _, v := range []uint64{1000, 2, 3} { // pool_liquidity, pool_equilibrium_fee, pool_weight
+		le(v)
+		buf.Write(make([]byte, 24))
+	}
+	var layout movePoolInfo
+	AssertNil(unmarshalMoveBcs(buf.Bytes(), &layout))
+	fromBcs := layout.poolInfo()
+
+	fromJson := newPoolInfo(map[string]interface{}{
+		"pool_address": map[string]interface{}{
+			"dola_chain_id": float64(DolaChainIdSui),
+			"dola_address":  []interface{}{float64(0xab), float64(0xcd)},
+		},
+		"pool_liquidity":       "1000",
+		"pool_equilibrium_fee": "2",
+		"pool_weight":          "3",
+	})
+	if fromBcs.DolaChainId != fromJson.DolaChainId || fromBcs.DolaAddress != fromJson.DolaAddress ||
+		fromBcs.PoolLiquidity.Cmp(fromJson.PoolLiquidity) != 0 ||
+		fromBcs.PoolEquilibriumFee.Cmp(fromJson.PoolEquilibriumFee) != 0 ||
+		fromBcs.PoolWeight.Cmp(fromJson.PoolWeight) != 0 {
+		t.Errorf("bcs %+v != json %+v", fromBcs, fromJson)
+	}
+	if fromJson.PoolEquilibriumFee.Int64() != 2 || fromJson.PoolWeight.Int64() != 3 {
+		t.Errorf("unexpected pool info %+v", fromJson)
+	}
+}
+
+func Test_DecodeLocalLendingEvent(t *testing.T) {
+	var buf bytes.Buffer
+	le := func(v any) { AssertNil(binary.Write(&buf, binary.LittleEndian, v)) }
+	le(uint64(9)) // nonce
+	buf.Write(make([]byte, 32))
+	buf.Write([]byte{1, 0xaa}) // dola_pool_address
+	le(uint64(100))
+	buf.WriteByte(CallTypeSupply)
+
+	event, err := DecodeLocalLendingEvent(types.SuiEvent{Bcs: lib.Base58(buf.Bytes()).String()})
+	AssertNil(err)
+	if event.Nonce != 9 || event.Amount != 100 || !bytes.Equal(event.DolaPoolAddress, []byte{0xaa}) {
+		t.Errorf("unexpected event %+v", event)
+	}
+}
@@ -0,0 +1,328 @@
+package gosuilending
+
+import (
+	"math/big"
+
+	"github.com/coming-chat/go-sui/v2/sui_types"
+	"github.com/coming-chat/go-sui/v2/types"
+)
+
+// Move layouts of lending events, field order must be the same as the move structs
+type (
+	moveLocalLendingEvent struct {
+		Nonce           uint64               `move:"nonce"`
+		Sender          sui_types.SuiAddress `move:"sender"`
+		DolaPoolAddress []byte               `move:"dola_pool_address"`
+		Amount          uint64               `move:"amount"`
+		CallType        uint8                `move:"call_type"`
+	}
+
+	moveLendingPortalEvent struct {
+		Nonce           uint64               `move:"nonce"`
+		Sender          sui_types.SuiAddress `move:"sender"`
+		DolaPoolAddress []byte               `move:"dola_pool_address"`
+		SourceChainId   uint16               `move:"source_chain_id"`
+		DstChainId      uint16               `move:"dst_chain_id"`
+		Receiver        []byte               `move:"receiver"`
+		Amount          uint64               `move:"amount"`
+		CallType        uint8                `move:"call_type"`
+	}
+
+	moveLendingCoreEvent struct {
+		Nonce           uint64 `move:"nonce"`
+		SenderUserId    uint64 `move:"sender_user_id"`
+		SourceChainId   uint16 `move:"source_chain_id"`
+		DstChainId      uint16 `move:"dst_chain_id"`
+		DolaPoolId      uint16 `move:"dola_pool_id"`
+		Receiver        []byte `move:"receiver"`
+		Amount          uint64 `move:"amount"`
+		LiquidateUserId uint64 `move:"liquidate_user_id"`
+		CallType        uint8  `move:"call_type"`
+	}
+
+	moveLendingCoreExecuteEvent struct {
+		UserId     uint64   `move:"user_id"`
+		Amount     *big.Int `move:"amount,u256"`
+		PoolId     uint16   `move:"pool_id"`
+		ViolatorId uint64   `move:"violator_id"`
+		CallType   uint8    `move:"call_type"`
+	}
+)
+
+// Move layouts of external_interfaces::interfaces query events
+type (
+	moveDolaAddress struct {
+		DolaChainId uint16 `move:"dola_chain_id"`
+		DolaAddress []byte `move:"dola_address"`
+	}
+
+	movePoolInfo struct {
+		PoolAddress        moveDolaAddress `move:"pool_address"`
+		PoolLiquidity      *big.Int        `move:"pool_liquidity,u256"`
+		PoolEquilibriumFee *big.Int        `move:"pool_equilibrium_fee,u256"`
+		PoolWeight         *big.Int        `move:"pool_weight,u256"`
+	}
+
+	moveTokenLiquidityInfo struct {
+		DolaPoolId     uint16   `move:"dola_pool_id"`
+		TokenLiquidity *big.Int `move:"token_liquidity,u256"`
+	}
+
+	moveAppLiquidityInfo struct {
+		AppId          uint16   `move:"app_id"`
+		DolaPoolId     uint16   `move:"dola_pool_id"`
+		TokenLiquidity *big.Int `move:"token_liquidity,u256"`
+	}
+
+	movePoolLiquidityInfo struct {
+		PoolAddress   moveDolaAddress `move:"pool_address"`
+		PoolLiquidity *big.Int        `move:"pool_liquidity,u256"`
+	}
+
+	moveAllPoolLiquidityInfo struct {
+		PoolInfos []movePoolInfo `move:"pool_infos"`
+	}
+
+	moveUserTokenDebt struct {
+		DebtAmount *big.Int `move:"debt_amount,u256"`
+		DebtValue  *big.Int `move:"debt_value,u256"`
+	}
+
+	moveCollateralItem struct {
+		DolaPoolId       uint16   `move:"dola_pool_id"`
+		BorrowApy        *big.Int `move:"borrow_apy,u256"`
+		SupplyApy        *big.Int `move:"supply_apy,u256"`
+		CollateralAmount *big.Int `move:"collateral_amount,u256"`
+		CollateralValue  *big.Int `move:"collateral_value,u256"`
+	}
+
+	moveDebtItem struct {
+		DolaPoolId uint16   `move:"dola_pool_id"`
+		BorrowApy  *big.Int `move:"borrow_apy,u256"`
+		SupplyApy  *big.Int `move:"supply_apy,u256"`
+		DebtAmount *big.Int `move:"debt_amount,u256"`
+		DebtValue  *big.Int `move:"debt_value,u256"`
+	}
+
+	moveUserLendingInfo struct {
+		HealthFactor         *big.Int             `move:"health_factor,u256"`
+		ProfitState          bool                 `move:"profit_state"`
+		NetApy               *big.Int             `move:"net_apy,u256"`
+		TotalSupplyApy       *big.Int             `move:"total_supply_apy,u256"`
+		TotalBorrowApy       *big.Int             `move:"total_borrow_apy,u256"`
+		CollateralInfos      []moveCollateralItem `move:"collateral_infos"`
+		TotalCollateralValue *big.Int             `move:"total_collateral_value,u256"`
+		DebtInfos            []moveDebtItem       `move:"debt_infos"`
+		TotalDebtValue       *big.Int             `move:"total_debt_value,u256"`
+	}
+
+	moveReserveInfo struct {
+		DolaPoolId            uint16         `move:"dola_pool_id"`
+		Pools                 []movePoolInfo `move:"pools"`
+		CollateralCoefficient *big.Int       `move:"collateral_coefficient,u256"`
+		BorrowCoefficient     *big.Int       `move:"borrow_coefficient,u256"`
+		BorrowApy             *big.Int       `move:"borrow_apy,u256"`
+		SupplyApy             *big.Int       `move:"supply_apy,u256"`
+		Reserve               *big.Int       `move:"reserve,u256"`
+		Debt                  *big.Int       `move:"debt,u256"`
+		UtilizationRate       *big.Int       `move:"utilization_rate,u256"`
+	}
+
+	moveAllReserveInfo struct {
+		ReserveInfos []moveReserveInfo `move:"reserve_infos"`
+	}
+
+	moveUserAllowedBorrow struct {
+		BorrowToken  string   `move:"borrow_token"`
+		BorrowAmount *big.Int `move:"borrow_amount,u256"`
+		Reason       string   `move:"reason"`
+	}
+
+	moveTokenPrice struct {
+		DolaPoolId uint16   `move:"dola_pool_id"`
+		Price      *big.Int `move:"price,u256"`
+		Decimal    uint8    `move:"decimal"`
+	}
+
+	moveAllOraclePrice struct {
+		TokenPrices []moveTokenPrice `move:"token_prices"`
+	}
+
+	moveDolaUserId struct {
+		DolaUserId uint64 `move:"dola_user_id"`
+	}
+
+	moveDolaUserAddresses struct {
+		DolaUserAddresses []moveDolaAddress `move:"dola_user_addresses"`
+	}
+
+	moveHealthFactor struct {
+		HealthFactor *big.Int `move:"health_factor,u256"`
+	}
+)
+
+func DecodeLocalLendingEvent(event types.SuiEvent) (*LocalLendingEvent, error) {
+	var layout moveLocalLendingEvent
+	if err := unmarshalEventBcs(event, &layout); err != nil {
+		return nil, err
+	}
+	header, err := parseMoveEventHeader(event)
+	if err != nil {
+		return nil, err
+	}
+	return &LocalLendingEvent{
+		MoveEventHeader: header,
+		Nonce:           layout.Nonce,
+		Sender:          layout.Sender.String(),
+		DolaPoolAddress: layout.DolaPoolAddress,
+		PoolAddress:     newDolaAddressFromBytes(DolaChainIdSui, layout.DolaPoolAddress),
+		Amount:          layout.Amount,
+		CallType:        int(layout.CallType),
+	}, nil
+}
+
+func DecodeLendingPortalEvent(event types.SuiEvent) (*LendingPortalEvent, error) {
+	var layout moveLendingPortalEvent
+	if err := unmarshalEventBcs(event, &layout); err != nil {
+		return nil, err
+	}
+	header, err := parseMoveEventHeader(event)
+	if err != nil {
+		return nil, err
+	}
+	return &LendingPortalEvent{
+		MoveEventHeader: header,
+		Nonce:           layout.Nonce,
+		Sender:          layout.Sender.String(),
+		DolaPoolAddress: layout.DolaPoolAddress,
+		PoolAddress:     newDolaAddressFromBytes(layout.SourceChainId, layout.DolaPoolAddress),
+		SourceChainId:   layout.SourceChainId,
+		DstChainId:      layout.DstChainId,
+		Receiver:        layout.Receiver,
+		ReceiverAddress: newUserAddressFromBytes(layout.DstChainId, layout.Receiver),
+		Amount:          layout.Amount,
+		CallType:        int(layout.CallType),
+	}, nil
+}
+
+func DecodeLendingCoreEvent(event types.SuiEvent) (*LendingCoreEvent, error) {
+	var layout moveLendingCoreEvent
+	if err := unmarshalEventBcs(event, &layout); err != nil {
+		return nil, err
+	}
+	header, err := parseMoveEventHeader(event)
+	if err != nil {
+		return nil, err
+	}
+	return &LendingCoreEvent{
+		MoveEventHeader: header,
+		Nonce:           layout.Nonce,
+		SenderUserId:    layout.SenderUserId,
+		SourceChainId:   layout.SourceChainId,
+		DstChainId:      layout.DstChainId,
+		DolaPoolId:      layout.DolaPoolId,
+		Receiver:        layout.Receiver,
+		ReceiverAddress: newUserAddressFromBytes(layout.DstChainId, layout.Receiver),
+		Amount:          layout.Amount,
+		LiquidateUserId: layout.LiquidateUserId,
+		CallType:        int(layout.CallType),
+	}, nil
+}
+
+func DecodeLendingCoreExecuteEvent(event types.SuiEvent) (*LendingCoreExecuteEvent, error) {
+	var layout moveLendingCoreExecuteEvent
+	if err := unmarshalEventBcs(event, &layout); err != nil {
+		return nil, err
+	}
+	header, err := parseMoveEventHeader(event)
+	if err != nil {
+		return nil, err
+	}
+	return &LendingCoreExecuteEvent{
+		MoveEventHeader: header,
+		UserId:          layout.UserId,
+		Amount:          layout.Amount,
+		PoolId:          layout.PoolId,
+		ViolatorId:      layout.ViolatorId,
+		CallType:        int(layout.CallType),
+	}, nil
+}
+
+func (p movePoolInfo) poolInfo() PoolInfo {
+	return PoolInfo{
+		PoolLiquidity:      p.PoolLiquidity,
+		DolaChainId:        p.PoolAddress.DolaChainId,
+		DolaAddress:        newDolaAddressFromBytes(p.PoolAddress.DolaChainId, p.PoolAddress.DolaAddress),
+		PoolEquilibriumFee: p.PoolEquilibriumFee,
+		PoolWeight:         p.PoolWeight,
+	}
+}
+
+func (r moveReserveInfo) reserveInfo() ReserveInfo {
+	pools := make([]PoolInfo, len(r.Pools))
+	for i := range r.Pools {
+		pools[i] = r.Pools[i].poolInfo()
+	}
+	return ReserveInfo{
+		BorrowApy:             int(r.BorrowApy.Int64()),
+		BorrowCoefficient:     r.BorrowCoefficient,
+		CollateralCoefficient: r.CollateralCoefficient,
+		Debt:                  r.Debt,
+		Reserve:               r.Reserve,
+		SupplyApy:             int(r.SupplyApy.Int64()),
+		UtilizationRate:       int(r.UtilizationRate.Int64()),
+		DolaPoolId:            r.DolaPoolId,
+		Pools:                 pools,
+	}
+}
+
+func (c moveCollateralItem) collateralItem() CollateralItem {
+	return CollateralItem{
+		CollateralAmount: c.CollateralAmount,
+		CollateralValue:  c.CollateralValue,
+		DolaPoolId:       c.DolaPoolId,
+		BorrowApy:        int(c.BorrowApy.Int64()),
+		SupplyApy:        int(c.SupplyApy.Int64()),
+	}
+}
+
+func (d moveDebtItem) debtItem() DebtItem {
+	return DebtItem{
+		DebtAmount: d.DebtAmount,
+		DebtValue:  d.DebtValue,
+		DolaPoolId: d.DolaPoolId,
+		BorrowApy:  int(d.BorrowApy.Int64()),
+		SupplyApy:  int(d.SupplyApy.Int64()),
+	}
+}
+
+func (u moveUserLendingInfo) userLendingInfo() *UserLendingInfo {
+	info := &UserLendingInfo{
+		TotalCollateralValue: u.TotalCollateralValue,
+		TotalDebtValue:       u.TotalDebtValue,
+		HealthFactor:         u.HealthFactor,
+		NetApy:               int(u.NetApy.Int64()),
+		TotalBorrowApy:       int(u.TotalBorrowApy.Int64()),
+		TotalSupplyApy:       int(u.TotalSupplyApy.Int64()),
+		CollateralInfos:      make([]CollateralItem, len(u.CollateralInfos)),
+		DebtInfos:            make([]DebtItem, len(u.DebtInfos)),
+	}
+	if !u.ProfitState {
+		info.NetApy = -info.NetApy
+	}
+	for i := range u.CollateralInfos {
+		info.CollateralInfos[i] = u.CollateralInfos[i].collateralItem()
+	}
+	for i := range u.DebtInfos {
+		info.DebtInfos[i] = u.DebtInfos[i].debtItem()
+	}
+	return info
+}
+
+func (p moveTokenPrice) dolaTokenPrice() DolaTokenPrice {
+	return DolaTokenPrice{
+		Decimal:    int(p.Decimal),
+		DolaPoolId: p.DolaPoolId,
+		Price:      p.Price,
+	}
+}
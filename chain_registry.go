@@ -0,0 +1,206 @@
+package gosuilending
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Dola chain ids, dola use the wormhole chain ids for evm chains
+const (
+	DolaChainIdSui       uint16 = 0
+	DolaChainIdAptos     uint16 = 1
+	DolaChainIdEthereum  uint16 = 2
+	DolaChainIdBsc       uint16 = 4
+	DolaChainIdPolygon   uint16 = 5
+	DolaChainIdAvalanche uint16 = 6
+	DolaChainIdArbitrum  uint16 = 23
+	DolaChainIdOptimism  uint16 = 24
+	DolaChainIdBase      uint16 = 30
+)
+
+type (
+	// AddressCodec convert between the chain native address string and the dola address bytes
+	AddressCodec interface {
+		Encode(address string) ([]byte, error)
+		Decode(data []byte) (string, error)
+	}
+
+	// Hex32Codec is 32 bytes address of sui and aptos, 0x + 64 lower hex
+	Hex32Codec struct{}
+
+	// EVMCodec is 20 bytes evm address, decoded with EIP-55 checksum
+	EVMCodec struct{}
+
+	// StringCodec keep the address as utf8 bytes, e.g. coin types of sui and aptos pools. Decode
+	// add the 0x prefix if missing and Encode remove it, as the move type_name has no 0x
+	StringCodec struct{}
+
+	// HexCodec is any length address as 0x + lower hex, used for unknown chains
+	HexCodec struct{}
+
+	// DolaChain is the address format of one dola chain, user and pool addresses may differ
+	// e.g. a sui user is an address while a sui pool is a coin type
+	DolaChain struct {
+		Id          uint16
+		Name        string
+		UserAddress AddressCodec
+		PoolAddress AddressCodec
+	}
+
+	ChainRegistry struct {
+		lock   sync.RWMutex
+		chains map[uint16]DolaChain
+	}
+)
+
+// DefaultChainRegistry is used by all parsers of this package, for the query results and the
+// address fields of the lending events
+var DefaultChainRegistry = NewDefaultChainRegistry()
+
+func NewChainRegistry() *ChainRegistry {
+	return &ChainRegistry{chains: make(map[uint16]DolaChain)}
+}
+
+// NewDefaultChainRegistry return a registry of sui, aptos and the known evm chains
+func NewDefaultChainRegistry() *ChainRegistry {
+	r := NewChainRegistry()
+	r.Register(DolaChain{Id: DolaChainIdSui, Name: "sui", UserAddress: Hex32Codec{}, PoolAddress: StringCodec{}})
+	r.Register(DolaChain{Id: DolaChainIdAptos, Name: "aptos", UserAddress: Hex32Codec{}, PoolAddress: StringCodec{}})
+	evmChains := map[uint16]string{
+		DolaChainIdEthereum:  "ethereum",
+		DolaChainIdBsc:       "bsc",
+		DolaChainIdPolygon:   "polygon",
+		DolaChainIdAvalanche: "avalanche",
+		DolaChainIdArbitrum:  "arbitrum",
+		DolaChainIdOptimism:  "optimism",
+		DolaChainIdBase:      "base",
+	}
+	for id, name := range evmChains {
+		r.Register(DolaChain{Id: id, Name: name, UserAddress: EVMCodec{}, PoolAddress: EVMCodec{}})
+	}
+	return r
+}
+
+// Register add or replace a chain
+func (r *ChainRegistry) Register(chain DolaChain) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.chains[chain.Id] = chain
+}
+
+// Chain return the registered chain, or a chain of HexCodec if unknown
+func (r *ChainRegistry) Chain(dolaChainId uint16) (DolaChain, bool) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	chain, ok := r.chains[dolaChainId]
+	if !ok {
+		return DolaChain{Id: dolaChainId, Name: fmt.Sprintf("chain%d", dolaChainId), UserAddress: HexCodec{}, PoolAddress: HexCodec{}}, false
+	}
+	return chain, true
+}
+
+func (r *ChainRegistry) DecodeUserAddress(dolaChainId uint16, data []byte) (string, error) {
+	chain, _ := r.Chain(dolaChainId)
+	return chain.UserAddress.Decode(data)
+}
+
+func (r *ChainRegistry) EncodeUserAddress(dolaChainId uint16, address string) ([]byte, error) {
+	chain, _ := r.Chain(dolaChainId)
+	return chain.UserAddress.Encode(address)
+}
+
+func (r *ChainRegistry) DecodePoolAddress(dolaChainId uint16, data []byte) (string, error) {
+	chain, _ := r.Chain(dolaChainId)
+	return chain.PoolAddress.Decode(data)
+}
+
+func (r *ChainRegistry) EncodePoolAddress(dolaChainId uint16, address string) ([]byte, error) {
+	chain, _ := r.Chain(dolaChainId)
+	return chain.PoolAddress.Encode(address)
+}
+
+func (Hex32Codec) Encode(address string) ([]byte, error) {
+	str := strings.TrimPrefix(strings.ToLower(address), "0x")
+	if len(str) > 64 {
+		return nil, fmt.Errorf("address %s is longer than 32 bytes", address)
+	}
+	// short sui addresses like 0x2 are left padded
+	data, err := hex.DecodeString(strings.Repeat("0", 64-len(str)) + str)
+	if err != nil {
+		return nil, fmt.Errorf("invalid address %s: %w", address, err)
+	}
+	return data, nil
+}
+
+func (Hex32Codec) Decode(data []byte) (string, error) {
+	if len(data) != 32 {
+		return "", fmt.Errorf("expect 32 bytes address, got %d", len(data))
+	}
+	return "0x" + hex.EncodeToString(data), nil
+}
+
+func (EVMCodec) Encode(address string) ([]byte, error) {
+	str := strings.TrimPrefix(strings.TrimPrefix(address, "0x"), "0X")
+	if len(str) != 40 {
+		return nil, fmt.Errorf("invalid evm address %s", address)
+	}
+	data, err := hex.DecodeString(str)
+	if err != nil {
+		return nil, fmt.Errorf("invalid evm address %s: %w", address, err)
+	}
+	// a mixed case address must have a valid checksum
+	if str != strings.ToLower(str) && str != strings.ToUpper(str) && checksumAddress(data) != "0x"+str {
+		return nil, fmt.Errorf("invalid checksum of evm address %s", address)
+	}
+	return data, nil
+}
+
+func (EVMCodec) Decode(data []byte) (string, error) {
+	if len(data) != 20 {
+		return "", fmt.Errorf("expect 20 bytes evm address, got %d", len(data))
+	}
+	return checksumAddress(data), nil
+}
+
+// checksumAddress format a 20 bytes address by EIP-55
+func checksumAddress(data []byte) string {
+	lower := hex.EncodeToString(data)
+	digest := keccak256([]byte(lower))
+	result := []byte(lower)
+	for i := range result {
+		nibble := digest[i/2]
+		if i%2 == 0 {
+			nibble >>= 4
+		}
+		if result[i] >= 'a' && nibble&0xf >= 8 {
+			result[i] -= 'a' - 'A'
+		}
+	}
+	return "0x" + string(result)
+}
+
+func (StringCodec) Encode(address string) ([]byte, error) {
+	if address == "" {
+		return nil, errors.New("empty address")
+	}
+	return []byte(strings.TrimPrefix(address, "0x")), nil
+}
+
+func (StringCodec) Decode(data []byte) (string, error) {
+	return "0x" + strings.TrimPrefix(string(data), "0x"), nil
+}
+
+func (HexCodec) Encode(address string) ([]byte, error) {
+	data, err := hex.DecodeString(strings.TrimPrefix(address, "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid address %s: %w", address, err)
+	}
+	return data, nil
+}
+
+func (HexCodec) Decode(data []byte) (string, error) {
+	return "0x" + hex.EncodeToString(data), nil
+}
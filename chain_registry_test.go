@@ -0,0 +1,66 @@
+package gosuilending
+
+import (
+	"testing"
+
+	"github.com/coming-chat/go-sui/v2/types"
+)
+
+func Test_ChainRegistry(t *testing.T) {
+	r := NewDefaultChainRegistry()
+	tests := []struct {
+		name     string
+		encode   func(uint16, string) ([]byte, error)
+		decode   func(uint16, []byte) (string, error)
+		chainId  uint16
+		address  string
+		dataSize int
+	}{
+		{"sui user", r.EncodeUserAddress, r.DecodeUserAddress, DolaChainIdSui, "0x00000000000000000000000000000000000000000000000000000000000000a1", 32},
+		{"sui pool", r.EncodePoolAddress, r.DecodePoolAddress, DolaChainIdSui, "0x2::sui::SUI", 11},
+		{"evm user", r.EncodeUserAddress, r.DecodeUserAddress, DolaChainIdBsc, "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed", 20},
+		{"unknown chain", r.EncodeUserAddress, r.DecodeUserAddress, 999, "0xabcd", 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := tt.encode(tt.chainId, tt.address)
+			AssertNil(err)
+			if len(data) != tt.dataSize {
+				t.Errorf("encode %s = %x", tt.address, data)
+			}
+			address, err := tt.decode(tt.chainId, data)
+			AssertNil(err)
+			if address != tt.address {
+				t.Errorf("decode %x = %s, want %s", data, address, tt.address)
+			}
+		})
+	}
+
+	if _, err := r.EncodeUserAddress(DolaChainIdBsc, "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAeD"); err == nil {
+		t.Errorf("EncodeUserAddress() should fail on a wrong checksum")
+	}
+	if _, err := r.DecodeUserAddress(DolaChainIdSui, []byte{1, 2}); err == nil {
+		t.Errorf("DecodeUserAddress() should fail on a short sui address")
+	}
+}
+
+func Test_eventAddresses(t *testing.T) {
+	receiver := []interface{}{}
+	for _, b := range []byte{0x5a, 0xae, 0xb6, 0x05, 0x3f, 0x3e, 0x94, 0xc9, 0xb9, 0xa0, 0x9f, 0x33, 0x66, 0x94, 0x35, 0xe7, 0xef, 0x1b, 0xea, 0xed} {
+		receiver = append(receiver, float64(b))
+	}
+	pool := []interface{}{}
+	for _, b := range []byte("2::sui::SUI") {
+		pool = append(pool, float64(b))
+	}
+	event, err := ParseLendingPortalEvent(types.SuiEvent{ParsedJson: map[string]interface{}{
+		"nonce": "1", "amount": "100", "sender": "0x1", "call_type": float64(CallTypeWithdraw),
+		"source_chain_id": float64(DolaChainIdSui), "dst_chain_id": float64(DolaChainIdBsc),
+		"receiver": receiver, "dola_pool_address": pool,
+	}})
+	AssertNil(err)
+	// evm addresses are checksummed, sui pools are coin types with 0x
+	if event.ReceiverAddress != "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed" || event.PoolAddress != "0x2::sui::SUI" {
+		t.Errorf("ReceiverAddress = %s, PoolAddress = %s", event.ReceiverAddress, event.PoolAddress)
+	}
+}
@@ -0,0 +1,208 @@
+package gosuilending
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/coming-chat/go-sui/v2/client"
+	"github.com/coming-chat/go-sui/v2/lib"
+	"github.com/coming-chat/go-sui/v2/move_types"
+	"github.com/coming-chat/go-sui/v2/sui_types"
+	"github.com/coming-chat/go-sui/v2/types"
+)
+
+const (
+	suiCoinType   = "0x2::sui::SUI"
+	coinPageLimit = 200
+)
+
+var ErrInsufficientBalance = errors.New("insufficient balance")
+
+type (
+	// CoinSelection is the fewest coins of one type whose balance cover Amount
+	CoinSelection struct {
+		Coins  []types.Coin
+		Total  *big.Int
+		Amount *big.Int
+	}
+
+	CoinSelector struct {
+		client *client.Client
+	}
+
+	// coinAmount stand for a coin vector argument the caller left empty, it is replaced
+	// by selected coins before the transaction is built
+	coinAmount struct {
+		coinType string
+		amount   *big.Int
+	}
+
+	// splitGasCoin stand for a vector<Coin<SUI>> argument split from the gas coin,
+	// only a programmable transaction can express it
+	splitGasCoin struct {
+		amount uint64
+	}
+)
+
+func (s *CoinSelection) CoinIds() []*sui_types.ObjectID {
+	ids := make([]*sui_types.ObjectID, len(s.Coins))
+	for i := range s.Coins {
+		ids[i] = &s.Coins[i].CoinObjectId
+	}
+	return ids
+}
+
+func NewCoinSelector(client *client.Client) *CoinSelector {
+	return &CoinSelector{client: client}
+}
+
+// SelectCoins page through all coins of coinType owned by owner and pick the fewest of them
+// covering amount. Coins in exclude, like the gas coin, are never picked
+func (s *CoinSelector) SelectCoins(ctx context.Context, owner sui_types.SuiAddress, coinType string, amount *big.Int, exclude ...sui_types.ObjectID) (*CoinSelection, error) {
+	excluded := make(map[sui_types.ObjectID]bool, len(exclude))
+	for _, id := range exclude {
+		excluded[id] = true
+	}
+
+	var (
+		coins  types.Coins
+		cursor *sui_types.ObjectID
+	)
+	for {
+		page, err := s.client.GetCoins(ctx, owner, &coinType, cursor, coinPageLimit)
+		if err != nil {
+			return nil, err
+		}
+		for _, coin := range page.Data {
+			if !excluded[coin.CoinObjectId] && coin.LockedUntilEpoch == nil {
+				coins = append(coins, coin)
+			}
+		}
+		if !page.HasNextPage || page.NextCursor == nil {
+			break
+		}
+		cursor = page.NextCursor
+	}
+	return pickCoins(coins, amount)
+}
+
+// pickCoins take the biggest coins first, which gives the fewest coins to merge
+func pickCoins(coins types.Coins, amount *big.Int) (*CoinSelection, error) {
+	sorted := make(types.Coins, len(coins))
+	copy(sorted, coins)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Balance.Uint64() > sorted[j].Balance.Uint64()
+	})
+
+	selection := &CoinSelection{Total: big.NewInt(0), Amount: amount}
+	for _, coin := range sorted {
+		if selection.Total.Cmp(amount) >= 0 {
+			break
+		}
+		selection.Coins = append(selection.Coins, coin)
+		selection.Total.Add(selection.Total, new(big.Int).SetUint64(coin.Balance.Uint64()))
+	}
+	if selection.Total.Cmp(amount) < 0 {
+		return nil, fmt.Errorf("%w: need %s, have %s", ErrInsufficientBalance, amount, selection.Total)
+	}
+	return selection, nil
+}
+
+// coinsArg return coins as is, or a coinAmount placeholder if the caller only gave an amount
+func coinsArg(coins any, coinType string, amount string) any {
+	if len(objectIdsOf(coins)) > 0 || coinType == "" {
+		return coins
+	}
+	value, ok := new(big.Int).SetString(amount, 10)
+	if !ok || value.Sign() <= 0 {
+		return coins
+	}
+	return coinAmount{coinType: coinType, amount: value}
+}
+
+func firstTypeArg(typeArgs []string) string {
+	if len(typeArgs) == 0 {
+		return ""
+	}
+	return typeArgs[0]
+}
+
+// selectCallCoins replace coinAmount placeholders of calls by the signer's coins, coins picked
+// by a step are not reused by later steps. If SUI coins other than the gas coin are not enough,
+// the amount is split from the gas coin
+func (c *Contract) selectCallCoins(ctx context.Context, signer sui_types.SuiAddress, calls []moveCall, callOptions CallOptions) ([]moveCall, error) {
+	var used []sui_types.ObjectID
+	if callOptions.Gas != nil {
+		used = append(used, *callOptions.Gas)
+	}
+	selector := NewCoinSelector(c.client)
+	result := make([]moveCall, len(calls))
+	for i, call := range calls {
+		result[i] = call
+		result[i].args = append([]any{}, call.args...)
+		for j, arg := range call.args {
+			placeholder, ok := arg.(coinAmount)
+			if !ok {
+				continue
+			}
+			selection, err := selector.SelectCoins(ctx, signer, placeholder.coinType, placeholder.amount, used...)
+			if err != nil {
+				if errors.Is(err, ErrInsufficientBalance) && callOptions.Gas != nil && isSuiCoinType(placeholder.coinType) && placeholder.amount.IsUint64() {
+					result[i].args[j] = splitGasCoin{amount: placeholder.amount.Uint64()}
+					continue
+				}
+				return nil, fmt.Errorf("select %s coins: %w", placeholder.coinType, err)
+			}
+			for _, coin := range selection.Coins {
+				used = append(used, coin.CoinObjectId)
+			}
+			result[i].args[j] = selection.CoinIds()
+		}
+	}
+	return result, nil
+}
+
+// needProgrammable report whether the call can't be built by the json rpc MoveCall
+func (call moveCall) needProgrammable() bool {
+	for _, arg := range call.args {
+		if _, ok := arg.(splitGasCoin); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func isSuiCoinType(coinType string) bool {
+	tag, err := parseTypeTag(coinType)
+	if err != nil || tag.Struct == nil {
+		return false
+	}
+	sui, _ := parseTypeTag(suiCoinType)
+	return tag.Struct.Address == sui.Struct.Address && tag.Struct.Module == sui.Struct.Module && tag.Struct.Name == sui.Struct.Name
+}
+
+// appendSplitGasCoin split amount from the gas coin and wrap it as vector<Coin<SUI>>
+func appendSplitGasCoin(ptb *sui_types.ProgrammableTransactionBuilder, amount uint64) (sui_types.Argument, error) {
+	amountArg, err := ptb.Pure(amount)
+	if err != nil {
+		return sui_types.Argument{}, err
+	}
+	split := ptb.Command(sui_types.Command{
+		SplitCoins: &struct {
+			Argument  sui_types.Argument
+			Arguments []sui_types.Argument
+		}{Argument: sui_types.Argument{GasCoin: &lib.EmptyEnum{}}, Arguments: []sui_types.Argument{amountArg}},
+	})
+	return ptb.Command(sui_types.Command{
+		MakeMoveVec: &struct {
+			TypeTag   *move_types.TypeTag `bcs:"optional"`
+			Arguments []sui_types.Argument
+		}{Arguments: []sui_types.Argument{{NestedResult: &struct {
+			Result1 uint16
+			Result2 uint16
+		}{Result1: *split.Result, Result2: 0}}}},
+	}), nil
+}
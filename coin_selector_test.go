@@ -0,0 +1,40 @@
+package gosuilending
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/coming-chat/go-sui/v2/types"
+)
+
+func Test_pickCoins(t *testing.T) {
+	var coins types.Coins
+	for i, balance := range []uint64{5, 30, 10, 20} {
+		coin := types.Coin{Balance: types.NewSafeSuiBigInt(balance)}
+		coin.CoinObjectId[31] = byte(i)
+		coins = append(coins, coin)
+	}
+
+	selection, err := pickCoins(coins, big.NewInt(45))
+	AssertNil(err)
+	if len(selection.Coins) != 2 || selection.Total.Cmp(big.NewInt(50)) != 0 {
+		t.Errorf("pickCoins() = %d coins, total %v", len(selection.Coins), selection.Total)
+	}
+
+	if _, err = pickCoins(coins, big.NewInt(66)); !errors.Is(err, ErrInsufficientBalance) {
+		t.Errorf("pickCoins() error = %v, want ErrInsufficientBalance", err)
+	}
+}
+
+func Test_isSuiCoinType(t *testing.T) {
+	for coinType, want := range map[string]bool{
+		"0x2::sui::SUI": true,
+		"0x0000000000000000000000000000000000000000000000000000000000000002::sui::SUI": true,
+		devUSDTAddress: false,
+	} {
+		if got := isSuiCoinType(coinType); got != want {
+			t.Errorf("isSuiCoinType(%s) = %v, want %v", coinType, got, want)
+		}
+	}
+}
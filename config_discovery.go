@@ -0,0 +1,315 @@
+package gosuilending
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/coming-chat/go-sui/v2/client"
+	"github.com/coming-chat/go-sui/v2/sui_types"
+	"github.com/coming-chat/go-sui/v2/types"
+)
+
+const (
+	// maxDiscoveredPackages bound the packages walked by DiscoverContractConfig
+	maxDiscoveredPackages = 32
+	// maxDiscoveredCalls bound the transactions calling a package searched by DiscoverContractConfig
+	maxDiscoveredCalls = 50
+)
+
+// createdObject is a shared or owned object created by a publish transaction
+type createdObject struct {
+	ObjectId sui_types.ObjectID
+	Type     string
+	Shared   bool
+}
+
+// DiscoverContractConfig fill the empty fields of base from the publish transactions of its
+// packages. The shared objects created there are matched with ContractObjectExpectations, and the
+// packages of their types are walked too, so the objects of the dola packages published separately
+// are found. When LendingPortalPackageId and BridgePoolPackageId are known, directly or from
+// PoolState, objects are matched by the exact parameter types of the calls using them and the
+// packages of these types are walked, like wormhole. Objects created after the publish of such a
+// package, like the wormhole State, are searched in the first transactions calling it. LendingCore
+// is searched in the package of Storage. Clock is 0x6 if empty.
+// The returned config is partial and the error lists the missing fields if any is not found
+func DiscoverContractConfig(ctx context.Context, client *client.Client, base ContractConfig) (ContractConfig, error) {
+	config := base
+	if config.Clock == "" {
+		config.Clock = suiClockObjectId
+	}
+	var queue []sui_types.ObjectID
+	for _, field := range []string{"LendingPortalPackageId", "ExternalInterfacePackageId", "BridgePoolPackageId"} {
+		if value := config.get(field); value != "" {
+			id, err := sui_types.NewObjectIdFromHex(value)
+			if err != nil {
+				return base, fmt.Errorf("invalid %s: %w", field, err)
+			}
+			queue = append(queue, *id)
+		}
+	}
+	if len(queue) == 0 {
+		return base, errors.New("no package id to discover from")
+	}
+
+	visited := make(map[sui_types.ObjectID]bool)
+	created, err := walkPackages(ctx, client, queue, visited)
+	if err != nil {
+		return base, err
+	}
+
+	// the struct names are enough to find PoolState and so the bridge pool package
+	named := config
+	issues := discoverObjects(&named, created, nil)
+	objectTypes, err := discoverObjectTypes(ctx, client, named)
+	if err != nil {
+		config = named
+		issues = append(issues, fmt.Sprintf("parameter types unknown: %v", err))
+	} else {
+		var dependencies []sui_types.ObjectID
+		for _, objectType := range objectTypes {
+			if tag, err := parseTypeTag(objectType); err == nil && tag.Struct != nil {
+				dependencies = append(dependencies, tag.Struct.Address)
+			}
+		}
+		more, err := walkPackages(ctx, client, dependencies, visited)
+		if err != nil {
+			return base, err
+		}
+		created = append(created, more...)
+		searched := make(map[sui_types.ObjectID]bool)
+		for _, expectation := range ContractObjectExpectations {
+			objectType, ok := objectTypes[expectation.Field]
+			if !ok || config.get(expectation.Field) != "" || hasObjectOfType(created, objectType) {
+				continue
+			}
+			tag, _ := parseTypeTag(objectType)
+			if searched[tag.Struct.Address] || isFrameworkAddress(tag.Struct.Address) {
+				continue
+			}
+			searched[tag.Struct.Address] = true
+			more, err := calledObjects(ctx, client, tag.Struct.Address)
+			if err != nil {
+				return base, fmt.Errorf("package %s: %w", tag.Struct.Address, err)
+			}
+			created = append(created, more...)
+		}
+		issues = discoverObjects(&config, created, objectTypes)
+	}
+
+	if missing := config.Missing(); len(missing) > 0 {
+		issues = append(issues, fmt.Sprintf("not found: %s", strings.Join(missing, ", ")))
+	}
+	if len(issues) > 0 {
+		return config, fmt.Errorf("discover contract config: %s", strings.Join(issues, "; "))
+	}
+	return config, nil
+}
+
+// walkPackages return the objects created by the publish transactions of queue and of the
+// packages of their types, visited packages are skipped
+func walkPackages(ctx context.Context, client *client.Client, queue []sui_types.ObjectID, visited map[sui_types.ObjectID]bool) ([]createdObject, error) {
+	var created []createdObject
+	for len(queue) > 0 && len(visited) < maxDiscoveredPackages {
+		pkg := queue[0]
+		queue = queue[1:]
+		if visited[pkg] || isFrameworkAddress(pkg) {
+			continue
+		}
+		visited[pkg] = true
+		objects, err := publishedObjects(ctx, client, pkg)
+		if err != nil {
+			return nil, fmt.Errorf("package %s: %w", pkg, err)
+		}
+		created = append(created, objects...)
+		for _, object := range objects {
+			if tag, err := parseTypeTag(object.Type); err == nil && tag.Struct != nil {
+				queue = append(queue, tag.Struct.Address)
+			}
+		}
+	}
+	return created, nil
+}
+
+// discoverObjectTypes return the parameter types of the config objects, see Contract.objectTypes
+func discoverObjectTypes(ctx context.Context, client *client.Client, config ContractConfig) (map[string]string, error) {
+	contract := &Contract{client: client}
+	for _, field := range []string{"LendingPortalPackageId", "BridgePoolPackageId"} {
+		id, err := sui_types.NewObjectIdFromHex(config.get(field))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", field, err)
+		}
+		*contract.configObjectField(field) = id
+	}
+	return contract.objectTypes(ctx)
+}
+
+// publishedObjects return the objects created by the publish transaction of pkg
+func publishedObjects(ctx context.Context, client *client.Client, pkg sui_types.ObjectID) ([]createdObject, error) {
+	object, err := client.GetObject(ctx, pkg, &types.SuiObjectDataOptions{ShowType: true, ShowPreviousTransaction: true})
+	if err != nil {
+		return nil, err
+	}
+	if object.Data == nil || object.Data.Type == nil || *object.Data.Type != "package" {
+		return nil, errors.New("not a package")
+	}
+	if object.Data.PreviousTransaction == nil {
+		return nil, errors.New("publish transaction unknown")
+	}
+	tx, err := client.GetTransactionBlock(ctx, *object.Data.PreviousTransaction, types.SuiTransactionBlockResponseOptions{ShowObjectChanges: true})
+	if err != nil {
+		return nil, err
+	}
+	return createdObjects(*tx), nil
+}
+
+func createdObjects(tx types.SuiTransactionBlockResponse) []createdObject {
+	var objects []createdObject
+	for _, change := range tx.ObjectChanges {
+		if created := change.Data.Created; created != nil {
+			objects = append(objects, createdObject{
+				ObjectId: created.ObjectId,
+				Type:     created.ObjectType,
+				Shared:   isSharedObject(&created.Owner),
+			})
+		}
+	}
+	return objects
+}
+
+// calledObjects return the objects created by the first transactions calling pkg
+func calledObjects(ctx context.Context, client *client.Client, pkg sui_types.ObjectID) ([]createdObject, error) {
+	filter := types.TransactionFilter{}
+	filter.MoveFunction = &struct {
+		Package  sui_types.ObjectID `json:"package"`
+		Module   string             `json:"module,omitempty"`
+		Function string             `json:"function,omitempty"`
+	}{Package: pkg}
+	limit := uint(maxDiscoveredCalls)
+	page, err := client.QueryTransactionBlocks(ctx, types.SuiTransactionBlockResponseQuery{
+		Filter:  &filter,
+		Options: &types.SuiTransactionBlockResponseOptions{ShowObjectChanges: true},
+	}, nil, &limit, false)
+	if err != nil {
+		return nil, err
+	}
+	var objects []createdObject
+	for _, tx := range page.Data {
+		objects = append(objects, createdObjects(tx)...)
+	}
+	return objects, nil
+}
+
+// hasObjectOfType report whether an object of created has the struct type objectType
+func hasObjectOfType(created []createdObject, objectType string) bool {
+	for _, object := range created {
+		if tag, err := parseTypeTag(object.Type); err == nil && tag.Struct != nil && structTypeName(tag) == objectType {
+			return true
+		}
+	}
+	return false
+}
+
+// discoverObjects set the empty fields of config to the created objects matching their
+// expectation and their type in objectTypes if any, a field matched by several objects is an
+// issue and left empty
+func discoverObjects(config *ContractConfig, created []createdObject, objectTypes map[string]string) []string {
+	var issues []string
+	for _, expectation := range ContractObjectExpectations {
+		if expectation.IsPackage || expectation.Name == "" || config.get(expectation.Field) != "" {
+			continue
+		}
+		var candidates []createdObject
+		for _, object := range created {
+			if (object.Shared || !expectation.Shared) && expectation.matchType(config, objectTypes, object.Type) {
+				candidates = append(candidates, object)
+			}
+		}
+		switch len(candidates) {
+		case 0:
+			continue
+		case 1:
+		default:
+			ids := make([]string, len(candidates))
+			for i, candidate := range candidates {
+				ids[i] = candidate.ObjectId.String()
+			}
+			sort.Strings(ids)
+			issues = append(issues, fmt.Sprintf("%s is ambiguous: %s", expectation.Field, strings.Join(ids, ", ")))
+			continue
+		}
+		_ = config.set(expectation.Field, candidates[0].ObjectId.String())
+		// the package of a type is known from the object, e.g. BridgePoolPackageId from PoolState
+		if isPackageField(expectation.Package) && config.get(expectation.Package) == "" {
+			tag, _ := parseTypeTag(candidates[0].Type)
+			_ = config.set(expectation.Package, tag.Struct.Address.String())
+		}
+	}
+	return issues
+}
+
+// matchType check objectType is the type of e.Field in objectTypes if known, otherwise that it
+// has the struct name and module of e, and is defined by the package of e if it is known
+func (e ObjectExpectation) matchType(config *ContractConfig, objectTypes map[string]string, objectType string) bool {
+	tag, err := parseTypeTag(objectType)
+	if err != nil || tag.Struct == nil {
+		return false
+	}
+	if expected, ok := objectTypes[e.Field]; ok {
+		return structTypeName(tag) == expected
+	}
+	if string(tag.Struct.Name) != e.Name || (e.Module != "" && string(tag.Struct.Module) != e.Module) {
+		return false
+	}
+	pkg := e.Package
+	switch {
+	case pkg == "" || strings.HasPrefix(pkg, "0x"):
+	case isPackageField(pkg):
+		pkg = config.get(pkg)
+	default:
+		// the package of an object field is only known from its type
+		packageType, ok := objectTypes[pkg]
+		if !ok {
+			return false
+		}
+		packageTag, _ := parseTypeTag(packageType)
+		pkg = packageTag.Struct.Address.String()
+	}
+	if pkg == "" {
+		return true
+	}
+	id, err := sui_types.NewObjectIdFromHex(pkg)
+	return err == nil && *id == tag.Struct.Address
+}
+
+// isPackageField report whether field is a package field of ContractConfig
+func isPackageField(field string) bool {
+	for _, expectation := range ContractObjectExpectations {
+		if expectation.Field == field {
+			return expectation.IsPackage
+		}
+	}
+	return false
+}
+
+// get return the value of a ContractConfig field
+func (c ContractConfig) get(field string) string {
+	v := reflect.ValueOf(c).FieldByName(field)
+	if !v.IsValid() {
+		return ""
+	}
+	return v.String()
+}
+
+// isFrameworkAddress is true for 0x1, 0x2 and 0x3, they are not published by a transaction
+func isFrameworkAddress(id sui_types.ObjectID) bool {
+	for i := 0; i < len(id)-1; i++ {
+		if id[i] != 0 {
+			return false
+		}
+	}
+	return id[len(id)-1] <= 3
+}
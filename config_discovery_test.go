@@ -0,0 +1,220 @@
+package gosuilending
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/coming-chat/go-sui/v2/client"
+	"github.com/coming-chat/go-sui/v2/lib"
+)
+
+func Test_discoverObjects(t *testing.T) {
+	const (
+		portalPackage = "0x00000000000000000000000000000000000000000000000000000000000000a1"
+		bridgePackage = "0x00000000000000000000000000000000000000000000000000000000000000b1"
+	)
+	config := ContractConfig{LendingPortalPackageId: portalPackage, Storage: "0x99"}
+	created := []createdObject{
+		{ObjectId: *toHex("0x11"), Type: portalPackage + "::lending::LendingPortal", Shared: true},
+		{ObjectId: *toHex("0x12"), Type: bridgePackage + "::bridge_pool::PoolState", Shared: true},
+		{ObjectId: *toHex("0x13"), Type: "0xc1::oracle::PriceOracle", Shared: true},
+		{ObjectId: *toHex("0x14"), Type: "0xc1::storage::Storage", Shared: true},
+		{ObjectId: *toHex("0x15"), Type: "0xc1::oracle::OracleCap", Shared: false},
+		{ObjectId: *toHex("0x16"), Type: "0xc1::pool_manager::PoolManagerInfo", Shared: false},
+		{ObjectId: *toHex("0x17"), Type: "0xc1::pool::PoolApproval", Shared: true},
+		{ObjectId: *toHex("0x18"), Type: "0xc2::pool::PoolApproval", Shared: true},
+		{ObjectId: *toHex("0x19"), Type: "0x2::package::UpgradeCap", Shared: false},
+	}
+	issues := discoverObjects(&config, created, nil)
+	if len(issues) != 1 || !strings.HasPrefix(issues[0], "PoolApproval is ambiguous") {
+		t.Errorf("discoverObjects() issues = %v", issues)
+	}
+	want := map[string]string{
+		"LendingPortal":       toHex("0x11").String(),
+		"PoolState":           toHex("0x12").String(),
+		"BridgePoolPackageId": bridgePackage,
+		"PriceOracle":         toHex("0x13").String(),
+		"Storage":             "0x99", // configured fields are kept
+		"PoolManagerInfo":     "",     // owned, not shared
+		"PoolApproval":        "",
+	}
+	for field, value := range want {
+		if got := config.get(field); got != value {
+			t.Errorf("%s = %q, want %q", field, got, value)
+		}
+	}
+
+	// exact parameter types pick the approval, core is from the package of storage
+	config = ContractConfig{}
+	objectTypes := map[string]string{
+		"PoolApproval": toHex("0xc2").String() + "::pool::PoolApproval",
+		"Storage":      toHex("0xc1").String() + "::storage::Storage",
+	}
+	created = append(created,
+		createdObject{ObjectId: *toHex("0x1a"), Type: "0xc1::lending_core::LendingCore"},
+		createdObject{ObjectId: *toHex("0x1b"), Type: "0xc2::lending_core::LendingCore"},
+	)
+	issues = discoverObjects(&config, created, objectTypes)
+	if len(issues) != 0 || config.PoolApproval != toHex("0x18").String() || config.LendingCore != toHex("0x1a").String() {
+		t.Errorf("discoverObjects() = %+v, issues %v", config, issues)
+	}
+}
+
+func Test_DiscoverContractConfig(t *testing.T) {
+	const (
+		portalPackage   = "0xa1"
+		externalPackage = "0xe1"
+		bridgePackage   = "0xb1"
+		dolaPackage     = "0xc1"
+		wormholePackage = "0xd1"
+	)
+	digestOf := func(pkg string) string {
+		return lib.Base58(toHex(pkg)[:]).String()
+	}
+	created := func(id, objectType string, shared bool) string {
+		owner := `{"AddressOwner":"0x1"}`
+		if shared {
+			owner = `{"Shared":{"initial_shared_version":1}}`
+		}
+		return fmt.Sprintf(`{"type":"created","sender":"0x1","owner":%s,"objectType":"%s","objectId":"%s","version":"1","digest":"%s"}`,
+			owner, objectType, toHex(id).String(), digestOf(id))
+	}
+	tx := func(pkg string, changes ...string) string {
+		return fmt.Sprintf(`{"digest":"%s","objectChanges":[%s]}`, digestOf(pkg), strings.Join(changes, ","))
+	}
+	// publish transactions by package, the wormhole State is created by a later call
+	publishes := map[string]string{
+		portalPackage:   tx(portalPackage, created("0x11", portalPackage+"::lending::LendingPortal", true)),
+		externalPackage: tx(externalPackage),
+		bridgePackage:   tx(bridgePackage, created("0x12", bridgePackage+"::bridge_pool::PoolState", true)),
+		dolaPackage: tx(dolaPackage,
+			created("0x13", dolaPackage+"::oracle::PriceOracle", true),
+			created("0x14", dolaPackage+"::storage::Storage", true),
+			created("0x15", dolaPackage+"::pool_manager::PoolManagerInfo", true),
+			created("0x16", dolaPackage+"::user_manager::UserManagerInfo", true),
+			created("0x17", dolaPackage+"::wormhole_adapter_core::CoreState", true),
+			created("0x18", dolaPackage+"::pool_manager::PoolApproval", true),
+			created("0x19", dolaPackage+"::lending_core::LendingCore", false),
+			created("0x1a", dolaPackage+"::state::State", true), // same name as the wormhole State
+		),
+		wormholePackage: tx(wormholePackage, created("0x1b", wormholePackage+"::setup::DeployerCap", false)),
+	}
+	calls := map[string]string{
+		wormholePackage: `{"data":[` + tx("0x1c", created("0x1d", wormholePackage+"::state::State", true)) + `],"hasNextPage":false}`,
+	}
+	param := func(address, module, name string) string {
+		return fmt.Sprintf(`{"MutableReference":{"Struct":{"address":"%s","module":"%s","name":"%s","typeArguments":[]}}}`, address, module, name)
+	}
+	functions := map[string]string{
+		"borrow_remote": `{"isEntry":true,"parameters":[` + strings.Join([]string{
+			param(dolaPackage, "pool_manager", "PoolApproval"),
+			param(dolaPackage, "storage", "Storage"),
+			param(dolaPackage, "oracle", "PriceOracle"),
+			param("0x2", "clock", "Clock"),
+			param(dolaPackage, "wormhole_adapter_core", "CoreState"),
+			param(portalPackage, "lending", "LendingPortal"),
+			param(wormholePackage, "state", "State"),
+			param(dolaPackage, "pool_manager", "PoolManagerInfo"),
+			param(dolaPackage, "user_manager", "UserManagerInfo"),
+		}, ",") + `,"U64","U64","U64","U64","U64","U64"]}`,
+		"send_binding": `{"isEntry":true,"parameters":[` + param(bridgePackage, "bridge_pool", "PoolState") + "," +
+			param(wormholePackage, "state", "State") + `,"U64","U64","U64","U64"]}`,
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Id     any               `json:"id"`
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		var first string
+		_ = json.Unmarshal(req.Params[0], &first)
+		result := "null"
+		switch req.Method {
+		case "sui_getObject":
+			for pkg := range publishes {
+				if toHex(pkg).String() == first {
+					result = fmt.Sprintf(`{"data":{"objectId":"%s","version":"1","digest":"%s","type":"package","previousTransaction":"%s"}}`,
+						first, digestOf(pkg), digestOf(pkg))
+				}
+			}
+		case "sui_getTransactionBlock":
+			for pkg, publish := range publishes {
+				if digestOf(pkg) == first {
+					result = publish
+				}
+			}
+		case "suix_queryTransactionBlocks":
+			var query struct {
+				Filter struct {
+					MoveFunction struct {
+						Package string `json:"package"`
+					}
+				} `json:"filter"`
+			}
+			_ = json.Unmarshal(req.Params[0], &query)
+			result = `{"data":[],"hasNextPage":false}`
+			for pkg, page := range calls {
+				if toHex(pkg).String() == query.Filter.MoveFunction.Package {
+					result = page
+				}
+			}
+		case "sui_getNormalizedMoveFunction":
+			var function string
+			_ = json.Unmarshal(req.Params[2], &function)
+			result = functions[function]
+		}
+		id, _ := json.Marshal(req.Id)
+		_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":` + string(id) + `,"result":` + result + `}`))
+	}))
+	defer server.Close()
+	client, err := client.Dial(server.URL)
+	AssertNil(err)
+
+	config, err := DiscoverContractConfig(context.Background(), client, ContractConfig{
+		LendingPortalPackageId:     portalPackage,
+		ExternalInterfacePackageId: externalPackage,
+		BridgePoolPackageId:        bridgePackage,
+	})
+	if err != nil {
+		t.Fatalf("DiscoverContractConfig() = %+v, %v", config, err)
+	}
+	want := map[string]string{
+		"LendingPortal":   "0x11",
+		"PoolState":       "0x12",
+		"PriceOracle":     "0x13",
+		"Storage":         "0x14",
+		"PoolManagerInfo": "0x15",
+		"UserManagerInfo": "0x16",
+		"CoreState":       "0x17",
+		"PoolApproval":    "0x18",
+		"LendingCore":     "0x19",
+		"WormholeState":   "0x1d",
+	}
+	for field, value := range want {
+		if got := config.get(field); got != toHex(value).String() {
+			t.Errorf("%s = %q, want %q", field, got, value)
+		}
+	}
+}
+
+func TestDiscoverContractConfig(t *testing.T) {
+	config, err := DiscoverContractConfig(context.Background(), getDevClient(), ContractConfig{
+		LendingPortalPackageId:     MainnetConfig.LendingPortalPackageId,
+		ExternalInterfacePackageId: MainnetConfig.ExternalInterfacePackageId,
+	})
+	if err != nil {
+		t.Fatalf("DiscoverContractConfig() = %+v, %v", config, err)
+	}
+	for _, field := range []string{"BridgePoolPackageId", "PoolManagerInfo", "PoolState", "PriceOracle", "Storage", "WormholeState", "UserManagerInfo", "Clock"} {
+		if got, want := config.get(field), MainnetConfig.get(field); got != want {
+			t.Errorf("%s = %s, want %s", field, got, want)
+		}
+	}
+}
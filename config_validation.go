@@ -0,0 +1,294 @@
+package gosuilending
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/coming-chat/go-sui/v2/move_types"
+	"github.com/coming-chat/go-sui/v2/sui_types"
+	"github.com/coming-chat/go-sui/v2/types"
+)
+
+type (
+	// ObjectExpectation is what the object of a ContractConfig field must be on chain
+	ObjectExpectation struct {
+		Field     string // ContractConfig field
+		IsPackage bool
+		Module    string // module of the struct, any module if empty
+		Name      string // struct name, only existence is checked if empty and not a package
+		Package   string // ContractConfig package field, object field whose type has the same package, or a fixed address like 0x2, any package if empty
+		Shared    bool
+	}
+
+	ConfigIssue struct {
+		Field    string
+		ObjectId string
+		Problem  string
+	}
+
+	// ConfigValidationError report every mismatch found by Contract.Validate
+	ConfigValidationError struct {
+		Issues []ConfigIssue
+	}
+)
+
+// ContractObjectExpectations is checked by Contract.Validate. The modules of the dola objects
+// differ between deployments, so only their struct names are listed. Validate also require the
+// objects passed to the lending and bridge calls to have the exact type of the call parameters,
+// see objectTypes
+var ContractObjectExpectations = []ObjectExpectation{
+	{Field: "LendingPortalPackageId", IsPackage: true},
+	{Field: "ExternalInterfacePackageId", IsPackage: true},
+	{Field: "BridgePoolPackageId", IsPackage: true},
+	{Field: "LendingPortal", Name: "LendingPortal", Package: "LendingPortalPackageId", Shared: true},
+	{Field: "PoolState", Name: "PoolState", Package: "BridgePoolPackageId", Shared: true},
+	{Field: "PoolManagerInfo", Name: "PoolManagerInfo", Shared: true},
+	{Field: "PriceOracle", Name: "PriceOracle", Shared: true},
+	{Field: "Storage", Name: "Storage", Shared: true},
+	{Field: "WormholeState", Name: "State", Shared: true},
+	{Field: "UserManagerInfo", Name: "UserManagerInfo", Shared: true},
+	{Field: "CoreState", Name: "CoreState", Shared: true},
+	{Field: "PoolApproval", Name: "PoolApproval", Shared: true},
+	{Field: "LendingCore", Name: "LendingCore", Package: "Storage"}, // not used by any call, it is from the package of Storage
+	{Field: "Clock", Module: "clock", Name: "Clock", Package: "0x2", Shared: true},
+}
+
+func (e *ConfigValidationError) Error() string {
+	problems := make([]string, len(e.Issues))
+	for i, issue := range e.Issues {
+		problems[i] = fmt.Sprintf("%s %s: %s", issue.Field, issue.ObjectId, issue.Problem)
+	}
+	return fmt.Sprintf("invalid contract config: %s", strings.Join(problems, "; "))
+}
+
+// Validate fetch every configured object and check it against ContractObjectExpectations and the
+// parameter types of the calls using it, all mismatches are returned in one *ConfigValidationError
+func (c *Contract) Validate(ctx context.Context) error {
+	objectTypes, err := c.objectTypes(ctx)
+	if err != nil {
+		return err
+	}
+	var ids []sui_types.ObjectID
+	seen := make(map[sui_types.ObjectID]bool)
+	for _, expectation := range ContractObjectExpectations {
+		id := c.configObjectId(expectation.Field)
+		if id == nil || seen[*id] {
+			continue
+		}
+		seen[*id] = true
+		ids = append(ids, *id)
+	}
+	responses, err := c.client.MultiGetObjects(ctx, ids, &types.SuiObjectDataOptions{ShowType: true, ShowOwner: true})
+	if err != nil {
+		return err
+	}
+	if len(responses) != len(ids) {
+		return fmt.Errorf("object response count mismatch")
+	}
+	objects := make(map[sui_types.ObjectID]types.SuiObjectResponse, len(ids))
+	for i, id := range ids {
+		objects[id] = responses[i]
+	}
+	return c.validateObjects(objects, objectTypes)
+}
+
+// signatureCalls are the calls passing every config object except LendingCore, the types of their
+// parameters are the types of the objects
+func (c *Contract) signatureCalls() []moveCall {
+	return []moveCall{
+		c.borrowRemoteCall(nil, BorrowArgs{}),
+		c.sendBindingCall(nil, BindingArgs{}),
+	}
+}
+
+// objectTypes return the struct type, without type arguments, of the parameter each config object
+// is passed to, by ContractConfig field. Types of parameters keep the package which defined them,
+// like the types of objects, so upgrades do not matter
+func (c *Contract) objectTypes(ctx context.Context) (map[string]string, error) {
+	// a probe contract with a distinct id per field tell which field is passed to a parameter
+	probe := &Contract{
+		lendingPortalPackageId:     c.lendingPortalPackageId,
+		externalInterfacePackageId: c.externalInterfacePackageId,
+		bridgePoolPackageId:        c.bridgePoolPackageId,
+	}
+	fields := make(map[sui_types.ObjectID]string)
+	for i, expectation := range ContractObjectExpectations {
+		field := probe.configObjectField(expectation.Field)
+		if expectation.IsPackage || field == nil {
+			continue
+		}
+		var id sui_types.ObjectID
+		for j := range id {
+			id[j] = 0xee
+		}
+		id[len(id)-1] = byte(i)
+		*field = &id
+		fields[id] = expectation.Field
+	}
+
+	objectTypes := make(map[string]string)
+	for _, call := range probe.signatureCalls() {
+		function, err := c.getMoveFunction(ctx, *call.packageId, call.module, call.function)
+		if err != nil {
+			return nil, fmt.Errorf("%s::%s: %w", call.module, call.function, err)
+		}
+		if len(function.Parameters) < len(call.args) {
+			return nil, fmt.Errorf("%s::%s has %d parameters, %d arguments are passed", call.module, call.function, len(function.Parameters), len(call.args))
+		}
+		for i, arg := range call.args {
+			id, ok := objectIdOf(arg)
+			if !ok || fields[id] == "" {
+				continue
+			}
+			param := function.Parameters[i].objectStruct()
+			if param == nil {
+				return nil, fmt.Errorf("%s::%s parameter %d of %s is not a struct", call.module, call.function, i, fields[id])
+			}
+			objectTypes[fields[id]] = normalizeHexAddress(param.Address) + "::" + param.Module + "::" + param.Name
+		}
+	}
+	return objectTypes, nil
+}
+
+// validateObjects check objects, objectTypes is the result of objectTypes
+func (c *Contract) validateObjects(objects map[sui_types.ObjectID]types.SuiObjectResponse, objectTypes map[string]string) error {
+	var issues []ConfigIssue
+	for _, expectation := range ContractObjectExpectations {
+		id := c.configObjectId(expectation.Field)
+		if id == nil {
+			issues = append(issues, ConfigIssue{Field: expectation.Field, Problem: "not configured"})
+			continue
+		}
+		if problem := c.checkObject(expectation, objects[*id], objectTypes); problem != "" {
+			issues = append(issues, ConfigIssue{Field: expectation.Field, ObjectId: id.String(), Problem: problem})
+		}
+	}
+	if len(issues) > 0 {
+		return &ConfigValidationError{Issues: issues}
+	}
+	return nil
+}
+
+// checkObject return the problem of object, empty if it matches expectation and its type in objectTypes
+func (c *Contract) checkObject(expectation ObjectExpectation, object types.SuiObjectResponse, objectTypes map[string]string) string {
+	if object.Data == nil {
+		return "object not found"
+	}
+	objectType := ""
+	if object.Data.Type != nil {
+		objectType = *object.Data.Type
+	}
+	if expectation.IsPackage {
+		if objectType != "package" {
+			return fmt.Sprintf("expect a package, got %s", objectType)
+		}
+		return ""
+	}
+	if expectation.Name != "" {
+		tag, err := parseTypeTag(objectType)
+		if err != nil || tag.Struct == nil {
+			return fmt.Sprintf("expect struct %s, got %s", expectation.Name, objectType)
+		}
+		if string(tag.Struct.Name) != expectation.Name ||
+			(expectation.Module != "" && string(tag.Struct.Module) != expectation.Module) {
+			return fmt.Sprintf("expect struct %s, got %s", expectation.typeName(), objectType)
+		}
+	}
+	if expectation.Package != "" {
+		tag, err := parseTypeTag(objectType)
+		pkg := c.expectedPackage(expectation.Package, objectTypes)
+		if err != nil || tag.Struct == nil || pkg == nil || !c.samePackage(*pkg, tag.Struct.Address) {
+			return fmt.Sprintf("expect a struct from package %s, got %s", expectation.Package, objectType)
+		}
+	}
+	if expected, ok := objectTypes[expectation.Field]; ok {
+		tag, err := parseTypeTag(objectType)
+		if err != nil || tag.Struct == nil || structTypeName(tag) != expected {
+			return fmt.Sprintf("expect %s, got %s", expected, objectType)
+		}
+	}
+	if expectation.Shared && !isSharedObject(object.Data.Owner) {
+		return "expect a shared object"
+	}
+	return ""
+}
+
+func (e ObjectExpectation) typeName() string {
+	if e.Module == "" {
+		return e.Name
+	}
+	return e.Module + "::" + e.Name
+}
+
+// expectedPackage resolve a ContractConfig package field, the package of the type of an object
+// field or a fixed address
+func (c *Contract) expectedPackage(pkg string, objectTypes map[string]string) *sui_types.ObjectID {
+	if objectType, ok := objectTypes[pkg]; ok {
+		tag, err := parseTypeTag(objectType)
+		if err != nil || tag.Struct == nil {
+			return nil
+		}
+		return &tag.Struct.Address
+	}
+	if strings.HasPrefix(pkg, "0x") {
+		id, err := sui_types.NewObjectIdFromHex(pkg)
+		if err != nil {
+			return nil
+		}
+		return id
+	}
+	return c.configObjectId(pkg)
+}
+
+// configObjectId return the id of a ContractConfig field
+func (c *Contract) configObjectId(field string) *sui_types.ObjectID {
+	if id := c.configObjectField(field); id != nil {
+		return *id
+	}
+	return nil
+}
+
+// configObjectField return the Contract field of a ContractConfig field
+func (c *Contract) configObjectField(field string) **sui_types.ObjectID {
+	switch field {
+	case "LendingPortalPackageId":
+		return &c.lendingPortalPackageId
+	case "ExternalInterfacePackageId":
+		return &c.externalInterfacePackageId
+	case "BridgePoolPackageId":
+		return &c.bridgePoolPackageId
+	case "PoolManagerInfo":
+		return &c.poolManagerInfo
+	case "PoolState":
+		return &c.poolState
+	case "PriceOracle":
+		return &c.priceOracle
+	case "Storage":
+		return &c.storage
+	case "WormholeState":
+		return &c.wormholeState
+	case "UserManagerInfo":
+		return &c.userManagerInfo
+	case "CoreState":
+		return &c.coreState
+	case "LendingPortal":
+		return &c.lendingPortal
+	case "LendingCore":
+		return &c.lendingCore
+	case "Clock":
+		return &c.clock
+	case "PoolApproval":
+		return &c.poolApproval
+	}
+	return nil
+}
+
+// structTypeName format the struct of tag without type arguments
+func structTypeName(tag move_types.TypeTag) string {
+	return tag.Struct.Address.String() + "::" + string(tag.Struct.Module) + "::" + string(tag.Struct.Name)
+}
+
+func isSharedObject(owner *types.ObjectOwner) bool {
+	return owner != nil && owner.ObjectOwnerInternal != nil && owner.Shared != nil
+}
@@ -0,0 +1,132 @@
+package gosuilending
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/coming-chat/go-sui/v2/sui_types"
+	"github.com/coming-chat/go-sui/v2/types"
+)
+
+func Test_ValidateObjects(t *testing.T) {
+	config := MainnetConfig
+	config.CoreState = "0x11"
+	config.LendingPortal = "0x12"
+	config.LendingCore = "0x13"
+	config.PoolApproval = "0x14"
+	contract, err := NewContract(nil, config)
+	AssertNil(err)
+
+	// parameter types of the signature calls, ids as returned by sui_getNormalizedMoveFunction
+	param := func(address, module, name string) string {
+		return fmt.Sprintf(`{"MutableReference":{"Struct":{"address":"%s","module":"%s","name":"%s","typeArguments":[]}}}`, address, module, name)
+	}
+	lendingPortalPackage := toHex(config.LendingPortalPackageId).String()
+	bridgePoolPackage := toHex(config.BridgePoolPackageId).String()
+	functions := map[string][]string{
+		lendingPortalPackage + "::lending::borrow_remote": {
+			param("0x3", "pool_manager", "PoolApproval"),
+			param("0x3", "storage", "Storage"),
+			param("0x3", "oracle", "PriceOracle"),
+			param("0x2", "clock", "Clock"),
+			param("0x3", "wormhole_adapter_core", "CoreState"),
+			param(lendingPortalPackage, "lending", "LendingPortal"),
+			param("0x4", "state", "State"),
+			param("0x3", "pool_manager", "PoolManagerInfo"),
+			param("0x3", "user_manager", "UserManagerInfo"),
+		},
+		bridgePoolPackage + "::bridge_pool::send_binding": {
+			param(bridgePoolPackage, "bridge_pool", "PoolState"),
+			param("0x4", "state", "State"),
+		},
+	}
+	contract.moveFunctions = make(map[string]*moveNormalizedFunction)
+	for key, params := range functions {
+		var function moveNormalizedFunction
+		data := `{"isEntry":true,"parameters":[` + strings.Join(params, ",") + `,"U64","U64","U64","U64","U64","U64"]}`
+		AssertNil(json.Unmarshal([]byte(data), &function))
+		contract.moveFunctions[key] = &function
+	}
+	objectTypes, err := contract.objectTypes(context.Background())
+	AssertNil(err)
+	if len(objectTypes) != 10 || objectTypes["WormholeState"] != normalizeHexAddress("0x4")+"::state::State" {
+		t.Fatalf("objectTypes() = %v", objectTypes)
+	}
+
+	var shared types.ObjectOwner
+	AssertNil(json.Unmarshal([]byte(`{"Shared":{"initial_shared_version":1}}`), &shared))
+	newObject := func(objectType string, owner *types.ObjectOwner) types.SuiObjectResponse {
+		return types.SuiObjectResponse{Data: &types.SuiObjectData{Type: &objectType, Owner: owner}}
+	}
+	objects := map[sui_types.ObjectID]types.SuiObjectResponse{
+		*toHex(config.LendingPortalPackageId):     newObject("package", nil),
+		*toHex(config.ExternalInterfacePackageId): newObject("package", nil),
+		*toHex(config.BridgePoolPackageId):        newObject("package", nil),
+		*toHex(config.LendingPortal):              newObject(config.LendingPortalPackageId+"::lending::LendingPortal", &shared),
+		*toHex(config.PoolState):                  newObject(config.BridgePoolPackageId+"::bridge_pool::PoolState", &shared),
+		*toHex(config.PoolManagerInfo):            newObject("0x3::pool_manager::PoolManagerInfo", &shared),
+		*toHex(config.PriceOracle):                newObject("0x3::oracle::PriceOracle", &shared),
+		*toHex(config.Storage):                    newObject("0x3::storage::Storage", &shared),
+		*toHex(config.WormholeState):              newObject("0x4::state::State", &shared),
+		*toHex(config.UserManagerInfo):            newObject("0x3::user_manager::UserManagerInfo", &shared),
+		*toHex(config.CoreState):                  newObject("0x3::wormhole_adapter_core::CoreState", &shared),
+		*toHex(config.PoolApproval):               newObject("0x3::pool_manager::PoolApproval", &shared),
+		*toHex(config.LendingCore):                newObject("0x3::lending_core::LendingCore", nil),
+		*toHex(config.Clock):                      newObject("0x2::clock::Clock", &shared),
+	}
+	AssertNil(contract.validateObjects(objects, objectTypes))
+
+	// swapped storage and pool manager, portal from another package, state from another module,
+	// missing core
+	objects[*toHex(config.Storage)], objects[*toHex(config.PoolManagerInfo)] =
+		objects[*toHex(config.PoolManagerInfo)], objects[*toHex(config.Storage)]
+	objects[*toHex(config.LendingPortal)] = newObject("0x5::lending::LendingPortal", &shared)
+	objects[*toHex(config.WormholeState)] = newObject("0x4::bridge_pool::State", &shared)
+	delete(objects, *toHex(config.LendingCore))
+	err = contract.validateObjects(objects, objectTypes)
+	var validationErr *ConfigValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("validateObjects() = %v", err)
+	}
+	var fields []string
+	for _, issue := range validationErr.Issues {
+		fields = append(fields, issue.Field)
+	}
+	if fmt.Sprint(fields) != "[LendingPortal PoolManagerInfo Storage WormholeState LendingCore]" {
+		t.Errorf("validateObjects() issues = %v", err)
+	}
+
+	// core from another package than storage
+	objects = map[sui_types.ObjectID]types.SuiObjectResponse{
+		*toHex(config.LendingCore): newObject("0x5::lending_core::LendingCore", nil),
+	}
+	if problem := contract.checkObject(ContractObjectExpectations[12], objects[*toHex(config.LendingCore)], objectTypes); problem == "" {
+		t.Errorf("checkObject() accepted a core from another package")
+	}
+}
+
+func TestContract_Validate(t *testing.T) {
+	contract := getDevContract()
+	contract.coreState = contract.storage
+	contract.lendingPortal = contract.storage
+	contract.lendingCore = contract.storage
+	contract.clock = toHex(suiClockObjectId)
+	contract.poolApproval = contract.storage
+	err := contract.Validate(context.Background())
+	// the dev contract does not have all objects, but the known ones must be valid
+	var validationErr *ConfigValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("Validate() = %v", err)
+	}
+	for _, issue := range validationErr.Issues {
+		switch issue.Field {
+		case "CoreState", "LendingPortal", "PoolApproval":
+		default:
+			t.Errorf("unexpected issue %+v", issue)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package gosuilending
 
 import (
 	"context"
+	"sync"
 
 	"github.com/coming-chat/go-sui/v2/client"
 	"github.com/coming-chat/go-sui/v2/sui_types"
@@ -9,13 +10,17 @@ import (
 )
 
 type CallOptions struct {
-	Gas       *sui_types.ObjectID
-	GasBudget uint64
+	Gas       *sui_types.ObjectID // the biggest SUI coin of signer if nil, the budget is then capped by its balance
+	GasBudget uint64              // budget of the dry run if AutoGasBudget, DefaultDryRunGasBudget if 0
+
+	AutoGasBudget   bool   // dry run the transaction and use the gas it cost as budget
+	GasSafetyMargin uint64 // percent added to the dry run gas, 20 -> 20%, DefaultGasSafetyMargin if 0
+	MaxGasBudget    uint64 // fail if the auto budget is bigger, no limit if 0
 }
 
 type SupplyArgs struct {
 	Pool          sui_types.ObjectID
-	DepositCoins  []*sui_types.ObjectID // vector<Coin<CoinType>>
+	DepositCoins  []*sui_types.ObjectID // vector<Coin<CoinType>>, selected from signer coins by DepositAmount if empty
 	DepositAmount string
 }
 
@@ -24,23 +29,38 @@ type WithdrawArgs struct {
 	Receiver       string
 	DstChain       string
 	Amount         string
-	RelayFeeCoins  []*sui_types.ObjectID
+	RelayFeeCoins  []*sui_types.ObjectID // vector<Coin<SUI>>, selected by RelayFeeAmount if empty
 	RelayFeeAmount string
 }
 
 type BorrowArgs struct {
-	Pool     sui_types.ObjectID
-	Receiver string
-	DstChain string
-	Amount   string
+	Pool           sui_types.ObjectID
+	Receiver       string
+	DstChain       string
+	Amount         string
+	RelayFeeCoins  []*sui_types.ObjectID // vector<Coin<SUI>>, only for BorrowRemote, selected by RelayFeeAmount if empty
+	RelayFeeAmount string
 }
 
 type RepayArgs struct {
 	Pool        sui_types.ObjectID
-	RepayCoins  []*sui_types.ObjectID // vector<Coin<CoinType>>
+	RepayCoins  []*sui_types.ObjectID // vector<Coin<CoinType>>, selected from signer coins by RepayAmount if empty
 	RepayAmount string
 }
 
+type LiquidateArgs struct {
+	DebtPool              sui_types.ObjectID
+	DebtCoins             []*sui_types.ObjectID // vector<Coin<DebtCoinType>>, selected by DebtAmount if empty
+	DebtAmount            string
+	CollateralChainId     uint16 // dola chain id of the collateral pool
+	CollateralPoolAddress string // collateral pool address on CollateralChainId, coin type for sui
+	ViolatorId            string // dola user id to be liquidated
+}
+
+type CollateralArgs struct {
+	DolaPoolIds []uint16 // vector<u16>, supplied pools to enable or cancel as collateral
+}
+
 type ContractConfig struct {
 	LendingPortalPackageId     string
 	ExternalInterfacePackageId string
@@ -72,8 +92,26 @@ type Contract struct {
 	userManagerInfo            *sui_types.ObjectID
 	coreState                  *sui_types.ObjectID
 	lendingPortal              *sui_types.ObjectID
+	lendingCore                *sui_types.ObjectID
 	clock                      *sui_types.ObjectID
 	poolApproval               *sui_types.ObjectID
+
+	// caches for programmable transaction building, see TransactionBuilder
+	cacheLock          sync.Mutex
+	sharedObjects      map[sui_types.ObjectID]sui_types.SequenceNumber
+	moveFunctions      map[string]*moveNormalizedFunction
+	packages           map[sui_types.ObjectID]*PackageInfo // upgrade chains by configured id, see ResolvePackages
+	lendingCorePackage *sui_types.ObjectID                 // package of the Storage type, see LendingCorePackageId
+}
+
+// moveCall is one move entry call, it can be sent alone by client.MoveCall or
+// appended as a step of a programmable transaction
+type moveCall struct {
+	packageId *sui_types.ObjectID
+	module    string
+	function  string
+	typeArgs  []string
+	args      []any
 }
 
 func NewContract(client *client.Client, config ContractConfig) (*Contract, error) {
@@ -112,6 +150,9 @@ func NewContract(client *client.Client, config ContractConfig) (*Contract, error
 	if contract.lendingPortal, err = sui_types.NewObjectIdFromHex(config.LendingPortal); err != nil {
 		return nil, err
 	}
+	if contract.lendingCore, err = sui_types.NewObjectIdFromHex(config.LendingCore); err != nil {
+		return nil, err
+	}
 	if contract.clock, err = sui_types.NewObjectIdFromHex(config.Clock); err != nil {
 		return nil, err
 	}
@@ -121,7 +162,15 @@ func NewContract(client *client.Client, config ContractConfig) (*Contract, error
 	return contract, nil
 }
 
+func (c *Contract) buildMoveCall(ctx context.Context, signer sui_types.SuiAddress, call moveCall, callOptions CallOptions) (*types.TransactionBytes, error) {
+	return c.buildCalls(ctx, signer, []moveCall{call}, callOptions)
+}
+
 func (c *Contract) Supply(ctx context.Context, signer sui_types.SuiAddress, typeArgs []string, supplyArgs SupplyArgs, callOptions CallOptions) (*types.TransactionBytes, error) {
+	return c.buildMoveCall(ctx, signer, c.supplyCall(typeArgs, supplyArgs), callOptions)
+}
+
+func (c *Contract) supplyCall(typeArgs []string, supplyArgs SupplyArgs) moveCall {
 	args := []any{
 		*c.storage,
 		*c.priceOracle,
@@ -130,14 +179,17 @@ func (c *Contract) Supply(ctx context.Context, signer sui_types.SuiAddress, type
 		*c.userManagerInfo,
 		*c.poolManagerInfo,
 		supplyArgs.Pool,
-		supplyArgs.DepositCoins,
+		coinsArg(supplyArgs.DepositCoins, firstTypeArg(typeArgs), supplyArgs.DepositAmount),
 		supplyArgs.DepositAmount,
 	}
-	resp, err := c.client.MoveCall(ctx, signer, *c.lendingPortalPackageId, "lending", "supply", typeArgs, args, callOptions.Gas, types.NewSafeSuiBigInt(callOptions.GasBudget))
-	return resp, err
+	return moveCall{c.lendingPortalPackageId, "lending", "supply", typeArgs, args}
 }
 
 func (c *Contract) WithdrawLocal(ctx context.Context, signer sui_types.SuiAddress, typeArgs []string, withdrawArgs WithdrawArgs, callOptions CallOptions) (*types.TransactionBytes, error) {
+	return c.buildMoveCall(ctx, signer, c.withdrawLocalCall(typeArgs, withdrawArgs), callOptions)
+}
+
+func (c *Contract) withdrawLocalCall(typeArgs []string, withdrawArgs WithdrawArgs) moveCall {
 	args := []any{
 		*c.storage,
 		*c.priceOracle,
@@ -148,11 +200,14 @@ func (c *Contract) WithdrawLocal(ctx context.Context, signer sui_types.SuiAddres
 		withdrawArgs.Pool,
 		withdrawArgs.Amount,
 	}
-	resp, err := c.client.MoveCall(ctx, signer, *c.lendingPortalPackageId, "lending", "withdraw_local", typeArgs, args, callOptions.Gas, types.NewSafeSuiBigInt(callOptions.GasBudget))
-	return resp, err
+	return moveCall{c.lendingPortalPackageId, "lending", "withdraw_local", typeArgs, args}
 }
 
 func (c *Contract) WithdrawRemote(ctx context.Context, signer sui_types.SuiAddress, typeArgs []string, withdrawArgs WithdrawArgs, callOptions CallOptions) (*types.TransactionBytes, error) {
+	return c.buildMoveCall(ctx, signer, c.withdrawRemoteCall(typeArgs, withdrawArgs), callOptions)
+}
+
+func (c *Contract) withdrawRemoteCall(typeArgs []string, withdrawArgs WithdrawArgs) moveCall {
 	args := []any{
 		*c.storage,
 		*c.priceOracle,
@@ -166,30 +221,62 @@ func (c *Contract) WithdrawRemote(ctx context.Context, signer sui_types.SuiAddre
 		withdrawArgs.Receiver,
 		withdrawArgs.DstChain,
 		withdrawArgs.Amount,
-		withdrawArgs.RelayFeeCoins,
+		coinsArg(withdrawArgs.RelayFeeCoins, suiCoinType, withdrawArgs.RelayFeeAmount),
 		withdrawArgs.RelayFeeAmount,
 	}
-	resp, err := c.client.MoveCall(ctx, signer, *c.lendingPortalPackageId, "lending", "withdraw_local", typeArgs, args, callOptions.Gas, types.NewSafeSuiBigInt(callOptions.GasBudget))
-	return resp, err
+	return moveCall{c.lendingPortalPackageId, "lending", "withdraw_remote", typeArgs, args}
 }
 
 func (c *Contract) BorrowLocal(ctx context.Context, signer sui_types.SuiAddress, typeArgs []string, borrowArgs BorrowArgs, callOptions CallOptions) (*types.TransactionBytes, error) {
+	return c.buildMoveCall(ctx, signer, c.borrowLocalCall(typeArgs, borrowArgs), callOptions)
+}
+
+func (c *Contract) borrowLocalCall(typeArgs []string, borrowArgs BorrowArgs) moveCall {
+	args := []any{
+		*c.poolApproval,
+		*c.storage,
+		*c.priceOracle,
+		*c.clock,
+		*c.lendingPortal,
+		*c.poolManagerInfo,
+		*c.userManagerInfo,
+		borrowArgs.Pool,
+		borrowArgs.Amount,
+	}
+	return moveCall{c.lendingPortalPackageId, "lending", "borrow_local", typeArgs, args}
+}
+
+// BorrowRemote borrow on sui and send the funds to borrowArgs.Receiver on borrowArgs.DstChain
+func (c *Contract) BorrowRemote(ctx context.Context, signer sui_types.SuiAddress, typeArgs []string, borrowArgs BorrowArgs, callOptions CallOptions) (*types.TransactionBytes, error) {
+	return c.buildMoveCall(ctx, signer, c.borrowRemoteCall(typeArgs, borrowArgs), callOptions)
+}
+
+func (c *Contract) borrowRemoteCall(typeArgs []string, borrowArgs BorrowArgs) moveCall {
 	args := []any{
 		*c.poolApproval,
 		*c.storage,
 		*c.priceOracle,
 		*c.clock,
+		*c.coreState,
 		*c.lendingPortal,
+		*c.wormholeState,
 		*c.poolManagerInfo,
 		*c.userManagerInfo,
 		borrowArgs.Pool,
+		borrowArgs.Receiver,
+		borrowArgs.DstChain,
 		borrowArgs.Amount,
+		coinsArg(borrowArgs.RelayFeeCoins, suiCoinType, borrowArgs.RelayFeeAmount),
+		borrowArgs.RelayFeeAmount,
 	}
-	resp, err := c.client.MoveCall(ctx, signer, *c.lendingPortalPackageId, "lending", "borrow_local", typeArgs, args, callOptions.Gas, types.NewSafeSuiBigInt(callOptions.GasBudget))
-	return resp, err
+	return moveCall{c.lendingPortalPackageId, "lending", "borrow_remote", typeArgs, args}
 }
 
 func (c *Contract) Repay(ctx context.Context, signer sui_types.SuiAddress, typeArgs []string, repayArgs RepayArgs, callOptions CallOptions) (*types.TransactionBytes, error) {
+	return c.buildMoveCall(ctx, signer, c.repayCall(typeArgs, repayArgs), callOptions)
+}
+
+func (c *Contract) repayCall(typeArgs []string, repayArgs RepayArgs) moveCall {
 	args := []any{
 		*c.storage,
 		*c.priceOracle,
@@ -197,9 +284,60 @@ func (c *Contract) Repay(ctx context.Context, signer sui_types.SuiAddress, typeA
 		*c.userManagerInfo,
 		*c.poolManagerInfo,
 		repayArgs.Pool,
-		repayArgs.RepayCoins,
+		coinsArg(repayArgs.RepayCoins, firstTypeArg(typeArgs), repayArgs.RepayAmount),
 		repayArgs.RepayAmount,
 	}
-	resp, err := c.client.MoveCall(ctx, signer, *c.lendingPortalPackageId, "lending", "repay", typeArgs, args, callOptions.Gas, types.NewSafeSuiBigInt(callOptions.GasBudget))
-	return resp, err
+	return moveCall{c.lendingPortalPackageId, "lending", "repay", typeArgs, args}
+}
+
+// Liquidate repay the debt of liquidateArgs.ViolatorId and seize its collateral, typeArgs is the debt coin type
+func (c *Contract) Liquidate(ctx context.Context, signer sui_types.SuiAddress, typeArgs []string, liquidateArgs LiquidateArgs, callOptions CallOptions) (*types.TransactionBytes, error) {
+	return c.buildMoveCall(ctx, signer, c.liquidateCall(typeArgs, liquidateArgs), callOptions)
+}
+
+func (c *Contract) liquidateCall(typeArgs []string, liquidateArgs LiquidateArgs) moveCall {
+	args := []any{
+		*c.storage,
+		*c.priceOracle,
+		*c.clock,
+		*c.lendingPortal,
+		*c.userManagerInfo,
+		*c.poolManagerInfo,
+		liquidateArgs.DebtPool,
+		coinsArg(liquidateArgs.DebtCoins, firstTypeArg(typeArgs), liquidateArgs.DebtAmount),
+		liquidateArgs.DebtAmount,
+		liquidateArgs.CollateralChainId,
+		liquidateArgs.CollateralPoolAddress,
+		liquidateArgs.ViolatorId,
+	}
+	return moveCall{c.lendingPortalPackageId, "lending", "liquidate", typeArgs, args}
+}
+
+// AsCollateral mark supplied pools as collateral again
+func (c *Contract) AsCollateral(ctx context.Context, signer sui_types.SuiAddress, collateralArgs CollateralArgs, callOptions CallOptions) (*types.TransactionBytes, error) {
+	return c.buildMoveCall(ctx, signer, c.asCollateralCall(collateralArgs), callOptions)
+}
+
+func (c *Contract) asCollateralCall(collateralArgs CollateralArgs) moveCall {
+	return moveCall{c.lendingPortalPackageId, "lending", "as_collateral", []string{}, c.collateralCallArgs(collateralArgs)}
+}
+
+// CancelAsCollateral keep supplied pools as isolated assets which are not counted as collateral
+func (c *Contract) CancelAsCollateral(ctx context.Context, signer sui_types.SuiAddress, collateralArgs CollateralArgs, callOptions CallOptions) (*types.TransactionBytes, error) {
+	return c.buildMoveCall(ctx, signer, c.cancelAsCollateralCall(collateralArgs), callOptions)
+}
+
+func (c *Contract) cancelAsCollateralCall(collateralArgs CollateralArgs) moveCall {
+	return moveCall{c.lendingPortalPackageId, "lending", "cancel_as_collateral", []string{}, c.collateralCallArgs(collateralArgs)}
+}
+
+func (c *Contract) collateralCallArgs(collateralArgs CollateralArgs) []any {
+	return []any{
+		*c.storage,
+		*c.priceOracle,
+		*c.clock,
+		*c.lendingPortal,
+		*c.userManagerInfo,
+		collateralArgs.DolaPoolIds,
+	}
 }
@@ -9,14 +9,14 @@ import (
 
 type (
 	BindingArgs struct {
-		WormholeMessageCoins  []sui_types.ObjectID // vector<Coin<SUI>>
+		WormholeMessageCoins  []sui_types.ObjectID // vector<Coin<SUI>>, selected by WormholeMessageAmount if empty
 		WormholeMessageAmount string
 		DolaChainId           uint16
 		BindAddress           string
 	}
 
 	UnbindingArgs struct {
-		WormholeMessageCoins  []sui_types.ObjectID // vector<Coin<SUI>>
+		WormholeMessageCoins  []sui_types.ObjectID // vector<Coin<SUI>>, selected by WormholeMessageAmount if empty
 		WormholeMessageAmount string
 		DolaChainId           uint16
 		UnbindAddress         string
@@ -24,27 +24,33 @@ type (
 )
 
 func (c *Contract) SendBinding(ctx context.Context, signer sui_types.SuiAddress, typeArgs []string, bindingArgs BindingArgs, callOptions CallOptions) (*types.TransactionBytes, error) {
+	return c.buildMoveCall(ctx, signer, c.sendBindingCall(typeArgs, bindingArgs), callOptions)
+}
+
+func (c *Contract) sendBindingCall(typeArgs []string, bindingArgs BindingArgs) moveCall {
 	args := []any{
 		*c.poolState,
 		*c.wormholeState,
-		bindingArgs.WormholeMessageCoins,
+		coinsArg(bindingArgs.WormholeMessageCoins, suiCoinType, bindingArgs.WormholeMessageAmount),
 		bindingArgs.WormholeMessageAmount,
 		bindingArgs.DolaChainId,
 		bindingArgs.BindAddress,
 	}
-	resp, err := c.client.MoveCall(ctx, signer, *c.bridgePoolPackageId, "bridge_pool", "send_binding", typeArgs, args, callOptions.Gas, types.NewSafeSuiBigInt(callOptions.GasBudget))
-	return resp, err
+	return moveCall{c.bridgePoolPackageId, "bridge_pool", "send_binding", typeArgs, args}
 }
 
 func (c *Contract) SendingUnbinding(ctx context.Context, signer sui_types.SuiAddress, typeArgs []string, unbindingArgs UnbindingArgs, callOptions CallOptions) (*types.TransactionBytes, error) {
+	return c.buildMoveCall(ctx, signer, c.sendingUnbindingCall(typeArgs, unbindingArgs), callOptions)
+}
+
+func (c *Contract) sendingUnbindingCall(typeArgs []string, unbindingArgs UnbindingArgs) moveCall {
 	args := []any{
 		*c.poolState,
 		*c.wormholeState,
-		unbindingArgs.WormholeMessageCoins,
+		coinsArg(unbindingArgs.WormholeMessageCoins, suiCoinType, unbindingArgs.WormholeMessageAmount),
 		unbindingArgs.WormholeMessageAmount,
 		unbindingArgs.DolaChainId,
 		unbindingArgs.UnbindAddress,
 	}
-	resp, err := c.client.MoveCall(ctx, signer, *c.bridgePoolPackageId, "bridge_pool", "send_unbinding", typeArgs, args, callOptions.Gas, types.NewSafeSuiBigInt(callOptions.GasBudget))
-	return resp, err
+	return moveCall{c.bridgePoolPackageId, "bridge_pool", "send_unbinding", typeArgs, args}
 }
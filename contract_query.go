@@ -6,7 +6,6 @@ import (
 	"errors"
 	"math/big"
 	"strconv"
-	"strings"
 
 	"github.com/coming-chat/go-sui/v2/sui_types"
 	"github.com/coming-chat/go-sui/v2/types"
@@ -16,14 +15,14 @@ type (
 	PoolInfo struct {
 		PoolLiquidity      *big.Int
 		DolaChainId        uint16
-		DolaAddress        string   // hex string. 0x123
+		DolaAddress        string   // native format of DolaChainId, see ChainRegistry
 		PoolEquilibriumFee *big.Int // u256
 		PoolWeight         *big.Int // u256
 	}
 
 	DolaUserAddress struct {
 		DolaChainId uint16
-		DolaAddress string // hex string. 0x123
+		DolaAddress string // native format of DolaChainId, see ChainRegistry
 	}
 
 	DolaTokenPrice struct {
@@ -177,9 +176,10 @@ func newDolaTokenPrice(priceInfo interface{}) DolaTokenPrice {
 
 func newDolaUserAddress(info interface{}) DolaUserAddress {
 	fields := info.(map[string]interface{})
+	dolaChainId := uint16(fields["dola_chain_id"].(float64))
 	return DolaUserAddress{
-		DolaChainId: uint16(fields["dola_chain_id"].(float64)),
-		DolaAddress: newUserAddress(fields["dola_address"]),
+		DolaChainId: dolaChainId,
+		DolaAddress: newUserAddress(dolaChainId, fields["dola_address"]),
 	}
 }
 
@@ -193,257 +193,324 @@ func newPoolInfo(info interface{}) PoolInfo {
 	poolInfo.DolaChainId = uint16(poolAddress["dola_chain_id"].(float64))
 	poolInfo.DolaAddress = newDolaAddress(poolInfo.DolaChainId, poolAddress["dola_address"])
 
-	poolInfo.PoolWeight, _ = new(big.Int).SetString(infoFields["pool_equilibrium_fee"].(string), 10)
+	poolInfo.PoolEquilibriumFee, _ = new(big.Int).SetString(infoFields["pool_equilibrium_fee"].(string), 10)
 
-	poolInfo.PoolEquilibriumFee, _ = new(big.Int).SetString(infoFields["pool_weight"].(string), 10)
+	poolInfo.PoolWeight, _ = new(big.Int).SetString(infoFields["pool_weight"].(string), 10)
 
 	return poolInfo
 }
 
-// newDolaAddress 把 []float64 转成 []byte 并转化成 hex string
+// newDolaAddress 把 []float64 转成 []byte 并转化成 pool 所在链的地址格式
 func newDolaAddress(dolaChainId uint16, data interface{}) string {
-	arrData := data.([]interface{})
-	u8arr := make([]byte, len(arrData))
-	for i := range arrData {
-		u8arr[i] = byte(arrData[i].(float64))
-	}
-	switch dolaChainId {
-	case 0, 1:
-		return "0x" + strings.TrimPrefix(string(u8arr), "0x")
-	default:
+	return newDolaAddressFromBytes(dolaChainId, parseByteSlice(data.([]interface{})))
+}
+
+// newDolaAddressFromBytes decode a pool address by DefaultChainRegistry, bytes not matching the
+// chain format are returned as hex
+func newDolaAddressFromBytes(dolaChainId uint16, u8arr []byte) string {
+	address, err := DefaultChainRegistry.DecodePoolAddress(dolaChainId, u8arr)
+	if err != nil {
 		return "0x" + hex.EncodeToString(u8arr)
 	}
+	return address
 }
 
-func newUserAddress(data interface{}) string {
-	return newDolaAddress(10, data)
+func newUserAddress(dolaChainId uint16, data interface{}) string {
+	return newUserAddressFromBytes(dolaChainId, parseByteSlice(data.([]interface{})))
 }
 
-func (c *Contract) GetDolaTokenLiquidity(ctx context.Context, signer sui_types.SuiAddress, dolaPoolId uint16, callOptions CallOptions) (liquidity *big.Int, err error) {
-	args := []any{
-		*c.poolManagerInfo,
-		dolaPoolId,
+// newUserAddressFromBytes decode a user address by DefaultChainRegistry, bytes not matching the
+// chain format are returned as hex
+func newUserAddressFromBytes(dolaChainId uint16, u8arr []byte) string {
+	address, err := DefaultChainRegistry.DecodeUserAddress(dolaChainId, u8arr)
+	if err != nil {
+		return "0x" + hex.EncodeToString(u8arr)
 	}
+	return address
+}
+
+// query is one interfaces::get_* call and the parsers of the event it emits, it can be sent
+// by Contract with a dry run, or by QueryClient with a dev inspect
+type query struct {
+	call   moveCall
+	parse  func(event types.SuiEvent) error // by ParsedJson
+	decode func(event types.SuiEvent) error // by Bcs, see bcs_events.go
+}
+
+// parseEvent decode the event by its bcs, the parsed json is only used if the node return no bcs
+func (q query) parseEvent(event types.SuiEvent) error {
+	if q.decode != nil && event.Bcs != "" {
+		return q.decode(event)
+	}
+	return parseEvent(event, q.parse)
+}
 
-	tx, err := c.client.MoveCall(ctx, signer, *c.externalInterfacePackageId, "interfaces", "get_dola_token_liquidity", []string{}, args, callOptions.Gas, types.NewSafeSuiBigInt(callOptions.GasBudget))
+func (c *Contract) dryRunQuery(ctx context.Context, signer sui_types.SuiAddress, q query, callOptions CallOptions) error {
+	call := c.resolveCall(q.call)
+	tx, err := c.client.MoveCall(ctx, signer, *call.packageId, call.module, call.function, call.typeArgs, call.args, callOptions.Gas, types.NewSafeSuiBigInt(callOptions.GasBudget))
 	if err != nil {
-		return
+		return err
 	}
 
 	effects, err := c.client.DryRunTransaction(ctx, tx.TxBytes)
 	if err != nil {
-		return
+		return err
 	}
+	return parseLastEvent(effects.Effects.Data, effects.Events, q.parseEvent)
+}
+
+func (c *Contract) interfacesQuery(function string, args []any, parse func(event types.SuiEvent) error, decode func(event types.SuiEvent) error) query {
+	return query{
+		call:   moveCall{c.externalInterfacePackageId, "interfaces", function, []string{}, args},
+		parse:  parse,
+		decode: decode,
+	}
+}
+
+func (c *Contract) GetDolaTokenLiquidity(ctx context.Context, signer sui_types.SuiAddress, dolaPoolId uint16, callOptions CallOptions) (liquidity *big.Int, err error) {
+	err = c.dryRunQuery(ctx, signer, c.dolaTokenLiquidityQuery(dolaPoolId, &liquidity), callOptions)
+	return
+}
 
-	err = parseLastEvent(effects, func(event types.SuiEvent) error {
+func (c *Contract) dolaTokenLiquidityQuery(dolaPoolId uint16, liquidity **big.Int) query {
+	args := []any{
+		*c.poolManagerInfo,
+		dolaPoolId,
+	}
+	return c.interfacesQuery("get_dola_token_liquidity", args, func(event types.SuiEvent) error {
 		tokenLiquidity := event.ParsedJson.(map[string]interface{})["token_liquidity"].(string)
-		liquidity, _ = big.NewInt(0).SetString(tokenLiquidity, 10)
+		*liquidity, _ = big.NewInt(0).SetString(tokenLiquidity, 10)
+		return nil
+	}, func(event types.SuiEvent) error {
+		var layout moveTokenLiquidityInfo
+		if err := unmarshalEventBcs(event, &layout); err != nil {
+			return err
+		}
+		*liquidity = layout.TokenLiquidity
 		return nil
 	})
-	return
 }
 
 func (c *Contract) GetAppTokenLiquidity(ctx context.Context, signer sui_types.SuiAddress, appId uint16, dolaPoolId uint16, callOptions CallOptions) (liquidity *big.Int, err error) {
+	err = c.dryRunQuery(ctx, signer, c.appTokenLiquidityQuery(appId, dolaPoolId, &liquidity), callOptions)
+	return
+}
+
+func (c *Contract) appTokenLiquidityQuery(appId uint16, dolaPoolId uint16, liquidity **big.Int) query {
 	args := []any{
 		*c.poolManagerInfo,
 		appId,
 		dolaPoolId,
 	}
-	tx, err := c.client.MoveCall(ctx, signer, *c.externalInterfacePackageId, "interfaces", "get_app_token_liquidity", []string{}, args, callOptions.Gas, types.NewSafeSuiBigInt(callOptions.GasBudget))
-	if err != nil {
-		return
-	}
-
-	effects, err := c.client.DryRunTransaction(ctx, tx.TxBytes)
-	if err != nil {
-		return
-	}
-
-	err = parseLastEvent(effects, func(event types.SuiEvent) error {
+	return c.interfacesQuery("get_app_token_liquidity", args, func(event types.SuiEvent) error {
 		tokenLiquidity := event.ParsedJson.(map[string]interface{})["token_liquidity"].(string)
 		var b bool
-		liquidity, b = big.NewInt(0).SetString(tokenLiquidity, 10)
+		*liquidity, b = big.NewInt(0).SetString(tokenLiquidity, 10)
 		if !b {
 			return errors.New("event parse failed: tokenLiquidity is not integer")
 		}
 		return nil
+	}, func(event types.SuiEvent) error {
+		var layout moveAppLiquidityInfo
+		if err := unmarshalEventBcs(event, &layout); err != nil {
+			return err
+		}
+		*liquidity = layout.TokenLiquidity
+		return nil
 	})
-	return
 }
 
 // GetPoolLiquidity return a pool liquidity on a chain
 func (c *Contract) GetPoolLiquidity(ctx context.Context, signer sui_types.SuiAddress, dolaChainId uint16, poolAddress string, callOptions CallOptions) (liquidity *big.Int, err error) {
+	err = c.dryRunQuery(ctx, signer, c.poolLiquidityQuery(dolaChainId, poolAddress, &liquidity), callOptions)
+	return
+}
+
+func (c *Contract) poolLiquidityQuery(dolaChainId uint16, poolAddress string, liquidity **big.Int) query {
 	args := []any{
 		*c.poolManagerInfo,
 		dolaChainId,
 		poolAddress,
 	}
-	tx, err := c.client.MoveCall(ctx, signer, *c.externalInterfacePackageId, "interfaces", "get_pool_liquidity", []string{}, args, callOptions.Gas, types.NewSafeSuiBigInt(callOptions.GasBudget))
-	if err != nil {
-		return
-	}
-
-	effects, err := c.client.DryRunTransaction(ctx, tx.TxBytes)
-	if err != nil {
-		return
-	}
-
-	err = parseLastEvent(effects, func(event types.SuiEvent) error {
+	return c.interfacesQuery("get_pool_liquidity", args, func(event types.SuiEvent) error {
 		tokenLiquidity := event.ParsedJson.(map[string]interface{})["pool_liquidity"].(string)
 		var b bool
-		liquidity, b = big.NewInt(0).SetString(tokenLiquidity, 10)
+		*liquidity, b = big.NewInt(0).SetString(tokenLiquidity, 10)
 		if !b {
 			return errors.New("event parse failed: tokenLiquidity is not integer")
 		}
 		return nil
+	}, func(event types.SuiEvent) error {
+		var layout movePoolLiquidityInfo
+		if err := unmarshalEventBcs(event, &layout); err != nil {
+			return err
+		}
+		*liquidity = layout.PoolLiquidity
+		return nil
 	})
-	return
 }
 
 // GetAllPoolLiquidity return all chain liquidity of a dola pool
 func (c *Contract) GetAllPoolLiquidity(ctx context.Context, signer sui_types.SuiAddress, dolaPoolId uint16, callOptions CallOptions) (poolInfos []PoolInfo, err error) {
+	err = c.dryRunQuery(ctx, signer, c.allPoolLiquidityQuery(dolaPoolId, &poolInfos), callOptions)
+	return
+}
+
+func (c *Contract) allPoolLiquidityQuery(dolaPoolId uint16, poolInfos *[]PoolInfo) query {
 	args := []any{
 		*c.poolManagerInfo,
 		dolaPoolId,
 	}
-	tx, err := c.client.MoveCall(ctx, signer, *c.externalInterfacePackageId, "interfaces", "get_all_pool_liquidity", []string{}, args, callOptions.Gas, types.NewSafeSuiBigInt(callOptions.GasBudget))
-	if err != nil {
-		return
-	}
-
-	effects, err := c.client.DryRunTransaction(ctx, tx.TxBytes)
-	if err != nil {
-		return
-	}
-
-	err = parseLastEvent(effects, func(event types.SuiEvent) error {
+	return c.interfacesQuery("get_all_pool_liquidity", args, func(event types.SuiEvent) error {
 		poolInfosData := event.ParsedJson.(map[string]interface{})["pool_infos"].([]interface{})
-		poolInfos = make([]PoolInfo, len(poolInfosData))
+		*poolInfos = make([]PoolInfo, len(poolInfosData))
 		for i := range poolInfosData {
-			poolInfos[i] = newPoolInfo(poolInfosData[i])
+			(*poolInfos)[i] = newPoolInfo(poolInfosData[i])
+		}
+		return nil
+	}, func(event types.SuiEvent) error {
+		var layout moveAllPoolLiquidityInfo
+		if err := unmarshalEventBcs(event, &layout); err != nil {
+			return err
+		}
+		*poolInfos = make([]PoolInfo, len(layout.PoolInfos))
+		for i := range layout.PoolInfos {
+			(*poolInfos)[i] = layout.PoolInfos[i].poolInfo()
 		}
 		return nil
 	})
-	return
 }
 
 func (c *Contract) GetUserTokenDebt(ctx context.Context, signer sui_types.SuiAddress, dolaUserId string, dolaPoolId uint16, callOptions CallOptions) (debtAmount *big.Int, debtValue *big.Int, err error) {
+	err = c.dryRunQuery(ctx, signer, c.userTokenDebtQuery(dolaUserId, dolaPoolId, &debtAmount, &debtValue), callOptions)
+	return
+}
+
+func (c *Contract) userTokenDebtQuery(dolaUserId string, dolaPoolId uint16, debtAmount **big.Int, debtValue **big.Int) query {
 	args := []any{
 		*c.storage,
 		*c.priceOracle,
 		dolaUserId,
 		dolaPoolId,
 	}
-	tx, err := c.client.MoveCall(ctx, signer, *c.externalInterfacePackageId, "interfaces", "get_user_token_debt", []string{}, args, callOptions.Gas, types.NewSafeSuiBigInt(callOptions.GasBudget))
-	if err != nil {
-		return
-	}
-
-	effects, err := c.client.DryRunTransaction(ctx, tx.TxBytes)
-	if err != nil {
-		return
-	}
-
-	err = parseLastEvent(effects, func(event types.SuiEvent) error {
+	return c.interfacesQuery("get_user_token_debt", args, func(event types.SuiEvent) error {
 		fields := event.ParsedJson.(map[string]interface{})
 		debtAmountStr := fields["debt_amount"].(string)
 		debtValueStr := fields["debt_value"].(string)
 		var ok bool
-		debtAmount, ok = big.NewInt(0).SetString(debtAmountStr, 10)
+		*debtAmount, ok = big.NewInt(0).SetString(debtAmountStr, 10)
 		if !ok {
 			return errors.New("debtAmount parse error")
 		}
-		debtValue, ok = big.NewInt(0).SetString(debtValueStr, 10)
+		*debtValue, ok = big.NewInt(0).SetString(debtValueStr, 10)
 		if !ok {
 			return errors.New("debtValue parse error")
 		}
 		return nil
+	}, func(event types.SuiEvent) error {
+		var layout moveUserTokenDebt
+		if err := unmarshalEventBcs(event, &layout); err != nil {
+			return err
+		}
+		*debtAmount, *debtValue = layout.DebtAmount, layout.DebtValue
+		return nil
 	})
-	return
 }
 
 func (c *Contract) GetUserCollateral(ctx context.Context, signer sui_types.SuiAddress, dolaUserId string, dolaPoolId uint16, callOptions CallOptions) (collateral CollateralItem, err error) {
+	err = c.dryRunQuery(ctx, signer, c.userCollateralQuery(dolaUserId, dolaPoolId, &collateral), callOptions)
+	return
+}
+
+func (c *Contract) userCollateralQuery(dolaUserId string, dolaPoolId uint16, collateral *CollateralItem) query {
 	args := []any{
 		*c.storage,
 		*c.priceOracle,
 		dolaUserId,
 		dolaPoolId,
 	}
-	tx, err := c.client.MoveCall(ctx, signer, *c.externalInterfacePackageId, "interfaces", "get_user_collateral", []string{}, args, callOptions.Gas, types.NewSafeSuiBigInt(callOptions.GasBudget))
-	if err != nil {
+	return c.interfacesQuery("get_user_collateral", args, func(event types.SuiEvent) (err error) {
+		*collateral, err = newCollateralItem(event.ParsedJson)
 		return
-	}
-
-	effects, err := c.client.DryRunTransaction(ctx, tx.TxBytes)
-	if err != nil {
-		return
-	}
-
-	err = parseLastEvent(effects, func(event types.SuiEvent) error {
-		collateral, err = newCollateralItem(event.ParsedJson)
+	}, func(event types.SuiEvent) error {
+		var layout moveCollateralItem
+		if err := unmarshalEventBcs(event, &layout); err != nil {
+			return err
+		}
+		*collateral = layout.collateralItem()
 		return nil
 	})
-	return
 }
 
 func (c *Contract) GetAllReserveInfo(ctx context.Context, signer sui_types.SuiAddress, callOptions CallOptions) (reserveInfos []ReserveInfo, err error) {
+	err = c.dryRunQuery(ctx, signer, c.allReserveInfoQuery(&reserveInfos), callOptions)
+	return
+}
+
+func (c *Contract) allReserveInfoQuery(reserveInfos *[]ReserveInfo) query {
 	args := []any{
 		*c.poolManagerInfo,
 		*c.storage,
 	}
-	tx, err := c.client.MoveCall(ctx, signer, *c.externalInterfacePackageId, "interfaces", "get_all_reserve_info", []string{}, args, callOptions.Gas, types.NewSafeSuiBigInt(callOptions.GasBudget))
-	if err != nil {
-		return
-	}
-
-	effects, err := c.client.DryRunTransaction(ctx, tx.TxBytes)
-	if err != nil {
-		return
-	}
-
-	err = parseLastEvent(effects, func(event types.SuiEvent) error {
+	return c.interfacesQuery("get_all_reserve_info", args, func(event types.SuiEvent) (err error) {
 		fields := event.ParsedJson.(map[string]interface{})
 		responseReserveInfos := fields["reserve_infos"].([]interface{})
-		reserveInfos = make([]ReserveInfo, len(responseReserveInfos))
+		*reserveInfos = make([]ReserveInfo, len(responseReserveInfos))
 		for i := range responseReserveInfos {
-			reserveInfos[i], err = newReserveInfo(responseReserveInfos[i])
+			(*reserveInfos)[i], err = newReserveInfo(responseReserveInfos[i])
 			if err != nil {
 				return err
 			}
 		}
 		return nil
+	}, func(event types.SuiEvent) error {
+		var layout moveAllReserveInfo
+		if err := unmarshalEventBcs(event, &layout); err != nil {
+			return err
+		}
+		*reserveInfos = make([]ReserveInfo, len(layout.ReserveInfos))
+		for i := range layout.ReserveInfos {
+			(*reserveInfos)[i] = layout.ReserveInfos[i].reserveInfo()
+		}
+		return nil
 	})
-	return
 }
 
 func (c *Contract) GetReserveInfo(ctx context.Context, signer sui_types.SuiAddress, dolaPoolId uint16, callOptions CallOptions) (reserveInfo *ReserveInfo, err error) {
+	err = c.dryRunQuery(ctx, signer, c.reserveInfoQuery(dolaPoolId, &reserveInfo), callOptions)
+	return
+}
+
+func (c *Contract) reserveInfoQuery(dolaPoolId uint16, reserveInfo **ReserveInfo) query {
 	args := []any{
 		*c.poolManagerInfo,
 		*c.storage,
 		dolaPoolId,
 	}
-	tx, err := c.client.MoveCall(ctx, signer, *c.externalInterfacePackageId, "interfaces", "get_reserve_info", []string{}, args, callOptions.Gas, types.NewSafeSuiBigInt(callOptions.GasBudget))
-	if err != nil {
-		return
-	}
-
-	effects, err := c.client.DryRunTransaction(ctx, tx.TxBytes)
-	if err != nil {
-		return
-	}
-
-	err = parseLastEvent(effects, func(event types.SuiEvent) error {
+	return c.interfacesQuery("get_reserve_info", args, func(event types.SuiEvent) error {
 		res, err := newReserveInfo(event.ParsedJson)
 		if err != nil {
 			return err
 		}
-		reserveInfo = &res
+		*reserveInfo = &res
+		return nil
+	}, func(event types.SuiEvent) error {
+		var layout moveReserveInfo
+		if err := unmarshalEventBcs(event, &layout); err != nil {
+			return err
+		}
+		res := layout.reserveInfo()
+		*reserveInfo = &res
 		return nil
 	})
-	return
 }
 
 func (c *Contract) GetUserAllowedBorrow(ctx context.Context, signer sui_types.SuiAddress, dolaUserId string, borrowPoolId uint16, callOptions CallOptions) (amount *big.Int, err error) {
+	err = c.dryRunQuery(ctx, signer, c.userAllowedBorrowQuery(dolaUserId, borrowPoolId, &amount), callOptions)
+	return
+}
+
+func (c *Contract) userAllowedBorrowQuery(dolaUserId string, borrowPoolId uint16, amount **big.Int) query {
 	args := []any{
 		*c.poolManagerInfo,
 		*c.storage,
@@ -451,48 +518,42 @@ func (c *Contract) GetUserAllowedBorrow(ctx context.Context, signer sui_types.Su
 		dolaUserId,
 		borrowPoolId,
 	}
-	tx, err := c.client.MoveCall(ctx, signer, *c.externalInterfacePackageId, "interfaces", "get_user_allowed_borrow", []string{}, args, callOptions.Gas, types.NewSafeSuiBigInt(callOptions.GasBudget))
-	if err != nil {
-		return
-	}
-
-	effects, err := c.client.DryRunTransaction(ctx, tx.TxBytes)
-	if err != nil {
-		return
-	}
-
-	err = parseLastEvent(effects, func(event types.SuiEvent) error {
+	return c.interfacesQuery("get_user_allowed_borrow", args, func(event types.SuiEvent) error {
 		fields := event.ParsedJson.(map[string]interface{})
-		amount, _ = big.NewInt(0).SetString(fields["borrow_amount"].(string), 10)
-		if amount.Cmp(big.NewInt(0)) == 0 {
+		*amount, _ = big.NewInt(0).SetString(fields["borrow_amount"].(string), 10)
+		if (*amount).Cmp(big.NewInt(0)) == 0 {
 			if fields["reason"] != "" && fields["reason"] != nil {
 				return errors.New(fields["reason"].(string))
 			}
 		}
 		return nil
+	}, func(event types.SuiEvent) error {
+		var layout moveUserAllowedBorrow
+		if err := unmarshalEventBcs(event, &layout); err != nil {
+			return err
+		}
+		*amount = layout.BorrowAmount
+		if layout.BorrowAmount.Sign() == 0 && layout.Reason != "" {
+			return errors.New(layout.Reason)
+		}
+		return nil
 	})
-	return
 }
 
 func (c *Contract) GetUserLendingInfo(ctx context.Context, signer sui_types.SuiAddress, dolaUserId string, callOptions CallOptions) (userLendingInfo *UserLendingInfo, err error) {
+	err = c.dryRunQuery(ctx, signer, c.userLendingInfoQuery(dolaUserId, &userLendingInfo), callOptions)
+	return
+}
+
+func (c *Contract) userLendingInfoQuery(dolaUserId string, result **UserLendingInfo) query {
 	args := []any{
 		*c.storage,
 		*c.priceOracle,
 		dolaUserId,
 	}
-	tx, err := c.client.MoveCall(ctx, signer, *c.externalInterfacePackageId, "interfaces", "get_user_lending_info", []string{}, args, callOptions.Gas, types.NewSafeSuiBigInt(callOptions.GasBudget))
-	if err != nil {
-		return
-	}
-
-	effects, err := c.client.DryRunTransaction(ctx, tx.TxBytes)
-	if err != nil {
-		return
-	}
-
-	err = parseLastEvent(effects, func(event types.SuiEvent) error {
+	return c.interfacesQuery("get_user_lending_info", args, func(event types.SuiEvent) (err error) {
 		fields := event.ParsedJson.(map[string]interface{})
-		userLendingInfo = &UserLendingInfo{}
+		userLendingInfo := &UserLendingInfo{}
 		userLendingInfo.TotalCollateralValue, _ = new(big.Int).SetString(fields["total_collateral_value"].(string), 10)
 		userLendingInfo.TotalDebtValue, _ = new(big.Int).SetString(fields["total_debt_value"].(string), 10)
 		userLendingInfo.HealthFactor, _ = new(big.Int).SetString(fields["health_factor"].(string), 10)
@@ -539,156 +600,178 @@ func (c *Contract) GetUserLendingInfo(ctx context.Context, signer sui_types.SuiA
 			}
 		}
 
+		*result = userLendingInfo
+		return nil
+	}, func(event types.SuiEvent) error {
+		var layout moveUserLendingInfo
+		if err := unmarshalEventBcs(event, &layout); err != nil {
+			return err
+		}
+		*result = layout.userLendingInfo()
 		return nil
 	})
-	return
 }
 
 func (c *Contract) GetOraclePrice(ctx context.Context, signer sui_types.SuiAddress, dolaPoolId uint16, callOptions CallOptions) (dolaTokenPrice DolaTokenPrice, err error) {
+	err = c.dryRunQuery(ctx, signer, c.oraclePriceQuery(dolaPoolId, &dolaTokenPrice), callOptions)
+	return
+}
+
+func (c *Contract) oraclePriceQuery(dolaPoolId uint16, dolaTokenPrice *DolaTokenPrice) query {
 	args := []any{
 		*c.priceOracle,
 		dolaPoolId,
 	}
-
-	tx, err := c.client.MoveCall(ctx, signer, *c.externalInterfacePackageId, "interfaces", "get_oracle_price", []string{}, args, callOptions.Gas, types.NewSafeSuiBigInt(callOptions.GasBudget))
-	if err != nil {
-		return
-	}
-
-	effects, err := c.client.DryRunTransaction(ctx, tx.TxBytes)
-	if err != nil {
-		return
-	}
-
-	err = parseLastEvent(effects, func(event types.SuiEvent) error {
-		dolaTokenPrice = newDolaTokenPrice(event.ParsedJson)
+	return c.interfacesQuery("get_oracle_price", args, func(event types.SuiEvent) error {
+		*dolaTokenPrice = newDolaTokenPrice(event.ParsedJson)
+		return nil
+	}, func(event types.SuiEvent) error {
+		var layout moveTokenPrice
+		if err := unmarshalEventBcs(event, &layout); err != nil {
+			return err
+		}
+		*dolaTokenPrice = layout.dolaTokenPrice()
 		return nil
 	})
-	return
 }
 
 func (c *Contract) GetAllOraclePrice(ctx context.Context, signer sui_types.SuiAddress, callOptions CallOptions) (prices []DolaTokenPrice, err error) {
+	err = c.dryRunQuery(ctx, signer, c.allOraclePriceQuery(&prices), callOptions)
+	return
+}
+
+func (c *Contract) allOraclePriceQuery(prices *[]DolaTokenPrice) query {
 	args := []any{
 		*c.storage,
 		*c.priceOracle,
 	}
-
-	tx, err := c.client.MoveCall(ctx, signer, *c.externalInterfacePackageId, "interfaces", "get_all_oracle_price", []string{}, args, callOptions.Gas, types.NewSafeSuiBigInt(callOptions.GasBudget))
-	if err != nil {
-		return
-	}
-
-	effects, err := c.client.DryRunTransaction(ctx, tx.TxBytes)
-	if err != nil {
-		return
-	}
-
-	err = parseLastEvent(effects, func(event types.SuiEvent) error {
+	return c.interfacesQuery("get_all_oracle_price", args, func(event types.SuiEvent) error {
 		fields := event.ParsedJson.(map[string]interface{})
 		tokenPrices := fields["token_prices"].([]interface{})
-		prices = make([]DolaTokenPrice, len(tokenPrices))
+		*prices = make([]DolaTokenPrice, len(tokenPrices))
 		for i, item := range tokenPrices {
-			prices[i] = newDolaTokenPrice(item)
+			(*prices)[i] = newDolaTokenPrice(item)
+		}
+		return nil
+	}, func(event types.SuiEvent) error {
+		var layout moveAllOraclePrice
+		if err := unmarshalEventBcs(event, &layout); err != nil {
+			return err
+		}
+		*prices = make([]DolaTokenPrice, len(layout.TokenPrices))
+		for i := range layout.TokenPrices {
+			(*prices)[i] = layout.TokenPrices[i].dolaTokenPrice()
 		}
 		return nil
 	})
-	return
 }
 
 // GetDolaUserId return dola_user_id for (dola_chain_id, address) pair
 // if not exist, an error return
 func (c *Contract) GetDolaUserId(ctx context.Context, signer sui_types.SuiAddress, dolaChainId uint16, user string, callOptions CallOptions) (userId string, err error) {
+	err = c.dryRunQuery(ctx, signer, c.dolaUserIdQuery(dolaChainId, user, &userId), callOptions)
+	return
+}
+
+func (c *Contract) dolaUserIdQuery(dolaChainId uint16, user string, userId *string) query {
 	args := []any{
 		*c.userManagerInfo,
 		dolaChainId,
 		user,
 	}
-	tx, err := c.client.MoveCall(ctx, signer, *c.externalInterfacePackageId, "interfaces", "get_dola_user_id", []string{}, args, callOptions.Gas, types.NewSafeSuiBigInt(callOptions.GasBudget))
-	if err != nil {
-		return
-	}
-
-	effects, err := c.client.DryRunTransaction(ctx, tx.TxBytes)
-	if err != nil {
-		return
-	}
-
-	err = parseLastEvent(effects, func(event types.SuiEvent) error {
+	return c.interfacesQuery("get_dola_user_id", args, func(event types.SuiEvent) error {
 		fields := event.ParsedJson.(map[string]interface{})
-		userId = fields["dola_user_id"].(string)
+		*userId = fields["dola_user_id"].(string)
+		return nil
+	}, func(event types.SuiEvent) error {
+		var layout moveDolaUserId
+		if err := unmarshalEventBcs(event, &layout); err != nil {
+			return err
+		}
+		*userId = strconv.FormatUint(layout.DolaUserId, 10)
 		return nil
 	})
-	return
 }
 
 func (c *Contract) GetDolaUserAddresses(ctx context.Context, signer sui_types.SuiAddress, dolaUserId string, callOptions CallOptions) (dolaUserAddresses []DolaUserAddress, err error) {
+	err = c.dryRunQuery(ctx, signer, c.dolaUserAddressesQuery(dolaUserId, &dolaUserAddresses), callOptions)
+	return
+}
+
+func (c *Contract) dolaUserAddressesQuery(dolaUserId string, dolaUserAddresses *[]DolaUserAddress) query {
 	args := []any{
 		*c.userManagerInfo,
 		dolaUserId,
 	}
-	tx, err := c.client.MoveCall(ctx, signer, *c.externalInterfacePackageId, "interfaces", "get_dola_user_addresses", []string{}, args, callOptions.Gas, types.NewSafeSuiBigInt(callOptions.GasBudget))
-	if err != nil {
-		return
-	}
-
-	effects, err := c.client.DryRunTransaction(ctx, tx.TxBytes)
-	if err != nil {
-		return
-	}
-
-	err = parseLastEvent(effects, func(event types.SuiEvent) error {
+	return c.interfacesQuery("get_dola_user_addresses", args, func(event types.SuiEvent) error {
 		fields := event.ParsedJson.(map[string]interface{})
 		addresses := fields["dola_user_addresses"].([]interface{})
-		dolaUserAddresses = make([]DolaUserAddress, len(addresses))
+		*dolaUserAddresses = make([]DolaUserAddress, len(addresses))
 		for i, item := range addresses {
-			dolaUserAddresses[i] = newDolaUserAddress(item)
+			(*dolaUserAddresses)[i] = newDolaUserAddress(item)
+		}
+		return nil
+	}, func(event types.SuiEvent) error {
+		var layout moveDolaUserAddresses
+		if err := unmarshalEventBcs(event, &layout); err != nil {
+			return err
+		}
+		*dolaUserAddresses = make([]DolaUserAddress, len(layout.DolaUserAddresses))
+		for i, address := range layout.DolaUserAddresses {
+			(*dolaUserAddresses)[i] = DolaUserAddress{
+				DolaChainId: address.DolaChainId,
+				DolaAddress: newUserAddressFromBytes(address.DolaChainId, address.DolaAddress),
+			}
 		}
 		return nil
 	})
-	return
 }
 
 func (c *Contract) GetUserHealthFactor(ctx context.Context, signer sui_types.SuiAddress, dolaUserId string, callOptions CallOptions) (healthFactor *big.Int, err error) {
+	err = c.dryRunQuery(ctx, signer, c.userHealthFactorQuery(dolaUserId, &healthFactor), callOptions)
+	return
+}
+
+func (c *Contract) userHealthFactorQuery(dolaUserId string, healthFactor **big.Int) query {
 	args := []any{
 		*c.storage,
 		*c.priceOracle,
 		dolaUserId,
 	}
-	tx, err := c.client.MoveCall(ctx, signer, *c.externalInterfacePackageId, "interfaces", "get_user_health_factor", []string{}, args, callOptions.Gas, types.NewSafeSuiBigInt(callOptions.GasBudget))
-	if err != nil {
-		return
-	}
-
-	effects, err := c.client.DryRunTransaction(ctx, tx.TxBytes)
-	if err != nil {
-		return
-	}
-
-	err = parseLastEvent(effects, func(event types.SuiEvent) error {
+	return c.interfacesQuery("get_user_health_factor", args, func(event types.SuiEvent) error {
 		fields := event.ParsedJson.(map[string]interface{})
-		healthFactor, _ = new(big.Int).SetString(fields["health_factor"].(string), 10)
+		*healthFactor, _ = new(big.Int).SetString(fields["health_factor"].(string), 10)
+		return nil
+	}, func(event types.SuiEvent) error {
+		var layout moveHealthFactor
+		if err := unmarshalEventBcs(event, &layout); err != nil {
+			return err
+		}
+		*healthFactor = layout.HealthFactor
 		return nil
 	})
-	return
 }
 
-func parseLastEvent(dryRunResponse *types.DryRunTransactionBlockResponse, f func(event types.SuiEvent) error) (err error) {
-	if !dryRunResponse.Effects.Data.IsSuccess() {
-		if nil == dryRunResponse.Effects.Data.V1 {
+func parseLastEvent(effects types.SuiTransactionBlockEffects, events []types.SuiEvent, f func(event types.SuiEvent) error) error {
+	if !effects.IsSuccess() {
+		if nil == effects.V1 {
 			return errors.New("parse event failed, no effects")
 		}
-		return errors.New(dryRunResponse.Effects.Data.V1.Status.Error)
+		return errors.New(effects.V1.Status.Error)
 	}
 
-	if len(dryRunResponse.Events) == 0 {
+	if len(events) == 0 {
 		return errors.New("invalid events")
 	}
+	return f(events[len(events)-1])
+}
 
+func parseEvent(event types.SuiEvent, f func(event types.SuiEvent) error) (err error) {
 	defer func() {
 		if merr := recover(); merr != nil {
 			err = errors.New("event parse failed")
 		}
 	}()
 
-	return f(dryRunResponse.Events[len(dryRunResponse.Events)-1])
+	return f(event)
 }
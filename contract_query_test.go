@@ -10,19 +10,10 @@ import (
 )
 
 const (
-	devnetRpcUrl              = "https://fullnode.mainnet.sui.io"
-	devLendingPortalPackageId = "0xc5b2a5049cd71586362d0c6a38e34cfaae7ea9ce6d5401a350506a15f817bf72"
-	devExternalInterfaces     = "0x93b49ef245f169342cb07e70b6a4835d4071594451a9df738acbb5ecdcac2e88"
-	devWormholeBridge         = "0x5306f64e312b581766351c07af79c72fcb1cd25147157fdc2f8ad76de9a3fb6a" //
+	devnetRpcUrl = "https://fullnode.mainnet.sui.io"
 
-	devUSDTAddress     = "c060006111016b8a020ad5b33834984a437aaa7d3c74c18e09a95d48aceab08c::coin::COIN"
-	devUSDTPoolId      = 1
-	devPoolManager     = "0x1be839a23e544e8d4ba7fab09eab50626c5cfed80f6a22faf7ff71b814689cfb"
-	devPoolState       = "0x5c9d9db2dd5f34154ee59686334f3504026809fa67afe5332837191ee6220586"
-	devPriceOracle     = "0x42afbffd3479b06f40c5576799b02ea300df36cf967adcd1ae15445270f572e2"
-	devStorage         = "0xe5a189b1858b207f2cf8c05a09d75bae4271c7a9a8f84a8c199c6896dc7c37e6"
-	devUserManagerInfo = "0xee633dc3fd1218d3bd9703fb9b98e6c8d7fdd8c8bf1ca2645ee40d65fb533a3e"
-	devWormholeState   = "0xaeab97f96cf9877fee2883315d459552b2b921edc16d7ceac6eab944dd88919c"
+	devUSDTAddress = "c060006111016b8a020ad5b33834984a437aaa7d3c74c18e09a95d48aceab08c::coin::COIN"
+	devUSDTPoolId  = 1
 
 	devTestUserId      = "72"
 	devTestUserAddress = "0x79e54dcebd85b45b6f447358d529a6c08687e3f98c6e9cd790238299fdedeabc"
@@ -32,15 +23,15 @@ const (
 func getDevContract() *Contract {
 	return &Contract{
 		client:                     getDevClient(),
-		lendingPortalPackageId:     toHex(devLendingPortalPackageId),
-		externalInterfacePackageId: toHex(devExternalInterfaces),
-		bridgePoolPackageId:        toHex(devWormholeBridge),
-		poolManagerInfo:            toHex(devPoolManager),
-		poolState:                  toHex(devPoolState),
-		priceOracle:                toHex(devPriceOracle),
-		storage:                    toHex(devStorage),
-		wormholeState:              toHex(devWormholeState),
-		userManagerInfo:            toHex(devUserManagerInfo),
+		lendingPortalPackageId:     toHex(MainnetConfig.LendingPortalPackageId),
+		externalInterfacePackageId: toHex(MainnetConfig.ExternalInterfacePackageId),
+		bridgePoolPackageId:        toHex(MainnetConfig.BridgePoolPackageId),
+		poolManagerInfo:            toHex(MainnetConfig.PoolManagerInfo),
+		poolState:                  toHex(MainnetConfig.PoolState),
+		priceOracle:                toHex(MainnetConfig.PriceOracle),
+		storage:                    toHex(MainnetConfig.Storage),
+		wormholeState:              toHex(MainnetConfig.WormholeState),
+		userManagerInfo:            toHex(MainnetConfig.UserManagerInfo),
 	}
 }
 
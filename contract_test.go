@@ -0,0 +1,23 @@
+package gosuilending
+
+import "testing"
+
+func Test_withdrawCalls(t *testing.T) {
+	contract := getDevContract()
+	contract.clock = toHex("0x6")
+	contract.coreState = toHex("0x11")
+	contract.lendingPortal = toHex("0x12")
+
+	local := contract.withdrawLocalCall(nil, WithdrawArgs{Amount: "1"})
+	if local.function != "withdraw_local" {
+		t.Errorf("withdrawLocalCall() function = %s", local.function)
+	}
+	// the remote call send the receiver and the destination chain, withdraw_local has no such parameters
+	remote := contract.withdrawRemoteCall(nil, WithdrawArgs{Receiver: "0xabc", DstChain: "5", Amount: "1"})
+	if remote.function != "withdraw_remote" {
+		t.Errorf("withdrawRemoteCall() function = %s", remote.function)
+	}
+	if len(remote.args) < 11 || remote.args[9] != "0xabc" || remote.args[10] != "5" {
+		t.Errorf("withdrawRemoteCall() args = %v", remote.args)
+	}
+}
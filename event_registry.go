@@ -0,0 +1,170 @@
+package gosuilending
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+
+	"github.com/coming-chat/go-sui/v2/move_types"
+	"github.com/coming-chat/go-sui/v2/types"
+)
+
+type (
+	// LendingEvent is implemented by all decoded lending events. The methods are prefixed by Event
+	// as the event structs already have CallType and Amount fields
+	LendingEvent interface {
+		Header() MoveEventHeader
+		EventCallType() int
+		EventAmount() *big.Int
+	}
+
+	LendingEventDecoder func(event types.SuiEvent) (LendingEvent, error)
+
+	// EventRegistry decode events by their full move type, package ids are normalized so
+	// 0x2::m::E and 0x00..02::m::E are the same type
+	EventRegistry struct {
+		lock     sync.RWMutex
+		decoders map[string]LendingEventDecoder
+	}
+)
+
+func (e *LocalLendingEvent) Header() MoveEventHeader { return e.MoveEventHeader }
+func (e *LocalLendingEvent) EventCallType() int      { return e.CallType }
+func (e *LocalLendingEvent) EventAmount() *big.Int   { return new(big.Int).SetUint64(e.Amount) }
+
+func (e *LendingPortalEvent) Header() MoveEventHeader { return e.MoveEventHeader }
+func (e *LendingPortalEvent) EventCallType() int      { return e.CallType }
+func (e *LendingPortalEvent) EventAmount() *big.Int   { return new(big.Int).SetUint64(e.Amount) }
+
+func (e *LendingCoreEvent) Header() MoveEventHeader { return e.MoveEventHeader }
+func (e *LendingCoreEvent) EventCallType() int      { return e.CallType }
+func (e *LendingCoreEvent) EventAmount() *big.Int   { return new(big.Int).SetUint64(e.Amount) }
+
+func (e *LendingCoreExecuteEvent) Header() MoveEventHeader { return e.MoveEventHeader }
+func (e *LendingCoreExecuteEvent) EventCallType() int      { return e.CallType }
+func (e *LendingCoreExecuteEvent) EventAmount() *big.Int   { return e.Amount }
+
+func NewEventRegistry() *EventRegistry {
+	return &EventRegistry{decoders: make(map[string]LendingEventDecoder)}
+}
+
+// NewLendingEventRegistry return a registry of the four lending events, see LendingEventTypes
+func NewLendingEventRegistry(lendingPortalPackageId, lendingCorePackageId string) (*EventRegistry, error) {
+	return newLendingEventRegistry(LendingEventTypes(lendingPortalPackageId, lendingCorePackageId))
+}
+
+// NewLendingEventRegistry return a registry of the LendingEventTypes of c, call ResolvePackages
+// first if a package may be upgraded
+func (c *Contract) NewLendingEventRegistry(ctx context.Context) (*EventRegistry, error) {
+	eventTypes, err := c.LendingEventTypes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return newLendingEventRegistry(eventTypes)
+}
+
+// newLendingEventRegistry register the decoders of the lending events to eventTypes, in
+// LendingEventTypes order
+func newLendingEventRegistry(eventTypes []string) (*EventRegistry, error) {
+	registry := NewEventRegistry()
+	decoders := []LendingEventDecoder{
+		func(event types.SuiEvent) (LendingEvent, error) { return ParseLocalLendingEvent(event) },
+		func(event types.SuiEvent) (LendingEvent, error) { return ParseLendingPortalEvent(event) },
+		func(event types.SuiEvent) (LendingEvent, error) { return ParseLendingCoreEvent(event) },
+		func(event types.SuiEvent) (LendingEvent, error) { return ParseLendingCoreExecuteEvent(event) },
+	}
+	for i, eventType := range eventTypes {
+		if err := registry.Register(eventType, decoders[i]); err != nil {
+			return nil, err
+		}
+	}
+	return registry, nil
+}
+
+// Register add or replace the decoder of eventType
+func (r *EventRegistry) Register(eventType string, decoder LendingEventDecoder) error {
+	key, err := normalizeMoveType(eventType)
+	if err != nil {
+		return fmt.Errorf("register %s: %w", eventType, err)
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.decoders[key] = decoder
+	return nil
+}
+
+// Registered report whether eventType has a decoder
+func (r *EventRegistry) Registered(eventType string) bool {
+	_, ok := r.decoder(eventType)
+	return ok
+}
+
+func (r *EventRegistry) Decode(event types.SuiEvent) (LendingEvent, error) {
+	decoder, ok := r.decoder(event.Type)
+	if !ok {
+		return nil, fmt.Errorf("unregistered event type %s", event.Type)
+	}
+	result, err := decoder(event)
+	if err != nil {
+		return nil, fmt.Errorf("decode %s: %w", event.Type, err)
+	}
+	return result, nil
+}
+
+func (r *EventRegistry) decoder(eventType string) (LendingEventDecoder, bool) {
+	key, err := normalizeMoveType(eventType)
+	if err != nil {
+		return nil, false
+	}
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	decoder, ok := r.decoders[key]
+	return decoder, ok
+}
+
+// normalizeMoveType format a move type with full length addresses
+func normalizeMoveType(str string) (string, error) {
+	tag, err := parseTypeTag(str)
+	if err != nil {
+		return "", err
+	}
+	return formatTypeTag(tag), nil
+}
+
+func formatTypeTag(tag move_types.TypeTag) string {
+	switch {
+	case tag.Bool != nil:
+		return "bool"
+	case tag.U8 != nil:
+		return "u8"
+	case tag.U16 != nil:
+		return "u16"
+	case tag.U32 != nil:
+		return "u32"
+	case tag.U64 != nil:
+		return "u64"
+	case tag.U128 != nil:
+		return "u128"
+	case tag.U256 != nil:
+		return "u256"
+	case tag.Address != nil:
+		return "address"
+	case tag.Signer != nil:
+		return "signer"
+	case tag.Vector != nil:
+		return "vector<" + formatTypeTag(*tag.Vector) + ">"
+	case tag.Struct != nil:
+		str := tag.Struct.Address.String() + "::" + string(tag.Struct.Module) + "::" + string(tag.Struct.Name)
+		if len(tag.Struct.TypeParams) > 0 {
+			params := make([]string, len(tag.Struct.TypeParams))
+			for i := range tag.Struct.TypeParams {
+				params[i] = formatTypeTag(tag.Struct.TypeParams[i])
+			}
+			str += "<" + strings.Join(params, ", ") + ">"
+		}
+		return str
+	}
+	return ""
+}
@@ -0,0 +1,37 @@
+package gosuilending
+
+import (
+	"testing"
+
+	"github.com/coming-chat/go-sui/v2/types"
+)
+
+func Test_EventRegistry(t *testing.T) {
+	registry, err := NewLendingEventRegistry("0x1", "0x2")
+	AssertNil(err)
+
+	event := types.SuiEvent{
+		Type: "0x0000000000000000000000000000000000000000000000000000000000000001::lending::LocalLendingEvent",
+		ParsedJson: map[string]interface{}{
+			"nonce": "1", "amount": "100", "sender": "0x1", "call_type": float64(CallTypeSupply), "dola_pool_address": []interface{}{float64(1)},
+		},
+	}
+	decoded, err := registry.Decode(event)
+	AssertNil(err)
+	if _, ok := decoded.(*LocalLendingEvent); !ok || decoded.EventCallType() != CallTypeSupply || decoded.EventAmount().Int64() != 100 {
+		t.Errorf("Decode() = %#v", decoded)
+	}
+
+	// same struct from an unregistered package
+	event.Type = "0x3::lending::LocalLendingEvent"
+	if _, err = registry.Decode(event); err == nil {
+		t.Errorf("Decode() should fail on unregistered event type")
+	}
+
+	AssertNil(registry.Register("0x3::lending::LocalLendingEvent", func(event types.SuiEvent) (LendingEvent, error) {
+		return ParseLocalLendingEvent(event)
+	}))
+	if !registry.Registered("0x0003::lending::LocalLendingEvent") {
+		t.Errorf("Registered() should normalize the package id")
+	}
+}
@@ -0,0 +1,449 @@
+package gosuilending
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/coming-chat/go-sui/v2/client"
+	"github.com/coming-chat/go-sui/v2/sui_types"
+	"github.com/coming-chat/go-sui/v2/types"
+)
+
+const (
+	defaultEventPageLimit   = 50
+	defaultMaxRetryInterval = 30 * time.Second
+)
+
+type (
+	// CursorStore keep the id of the last delivered event of every subscribed event type
+	CursorStore interface {
+		LoadCursor(eventType string) (*types.EventId, error) // nil if never saved
+		SaveCursor(eventType string, cursor types.EventId) error
+	}
+
+	MemoryCursorStore struct {
+		lock    sync.Mutex
+		cursors map[string]types.EventId
+	}
+
+	// FileCursorStore save cursors of all event types as a json file
+	FileCursorStore struct {
+		lock sync.Mutex
+		path string
+	}
+
+	EventSubscriberOptions struct {
+		EventTypes   []string       // full move event types, e.g. LendingEventTypes(...)
+		Store        CursorStore    // MemoryCursorStore if nil
+		PollInterval time.Duration  // wait between polls when no new event, 1s if 0
+		PageLimit    uint           // events of each query, 50 if 0
+		Registry     *EventRegistry // decode events by the registry if set, else by the four lending Parse functions
+
+		// failed queries are retried after PollInterval, doubled on every failure up to MaxRetryInterval,
+		// 30s if 0. Run fail after RetryLimit failures in a row, it retry until ctx is done if 0
+		MaxRetryInterval time.Duration
+		RetryLimit       int
+		// Run return on an event which can not be decoded. Else the event is reported as an
+		// *EventDecodeError and skipped, its cursor is saved as if it was delivered
+		StopOnDecodeError bool
+		// receive the skipped events of Run, Subscribe send them to its error channel instead
+		OnDecodeError func(err *EventDecodeError)
+	}
+
+	// EventDecodeError is an event skipped by the subscriber
+	EventDecodeError struct {
+		EventType string
+		Event     types.SuiEvent
+		Err       error
+	}
+
+	// SubscribedEvent is one decoded lending event, Event is *LocalLendingEvent, *LendingPortalEvent,
+	// *LendingCoreEvent or *LendingCoreExecuteEvent
+	SubscribedEvent struct {
+		EventType string
+		Event     any
+		Raw       types.SuiEvent
+	}
+
+	// EventSubscriber poll lending events by move event type and deliver them decoded, in order
+	EventSubscriber struct {
+		client  *client.Client
+		options EventSubscriberOptions
+	}
+)
+
+// lendingEventStructs are the lending events in LendingEventTypes order, from the lending portal
+// package or the lending core package
+var lendingEventStructs = [...]struct {
+	core         bool
+	module, name string
+}{
+	{false, "lending", LocalLendingEventName},
+	{false, "lending", LendingPortalEventName},
+	{true, "wormhole_adapter", LendingCoreEventName},
+	{true, "logic", LendingCoreExecuteEventName},
+}
+
+// lendingEventType return the move type of the lending event name in package pkg
+func lendingEventType(pkg, name string) string {
+	for _, event := range lendingEventStructs {
+		if event.name == name {
+			return pkg + "::" + event.module + "::" + name
+		}
+	}
+	panic("unknown lending event " + name)
+}
+
+// LendingEventTypes return the move event types of the lending portal and lending core packages.
+// ContractConfig has no lending core package, it is the package defining the type of the Storage
+// object, see Contract.LendingEventTypes. The modules are these of the lending core this package is
+// written against, lending events of other modules are not subscribed. Events keep the package
+// defining their struct, pass PackageInfo.Original if a package is upgraded
+func LendingEventTypes(lendingPortalPackageId, lendingCorePackageId string) []string {
+	eventTypes := make([]string, len(lendingEventStructs))
+	for i, event := range lendingEventStructs {
+		pkg := lendingPortalPackageId
+		if event.core {
+			pkg = lendingCorePackageId
+		}
+		eventTypes[i] = lendingEventType(pkg, event.name)
+	}
+	return eventTypes
+}
+
+// LendingEventTypes return the LendingEventTypes of the lending portal package and the lending core
+// package of c, see LendingCorePackageId. Once ResolvePackages is called, every event use the
+// package defining its struct, so the events of upgraded packages are found
+func (c *Contract) LendingEventTypes(ctx context.Context) ([]string, error) {
+	lendingCore, err := c.LendingCorePackageId(ctx)
+	if err != nil {
+		return nil, err
+	}
+	eventTypes := make([]string, len(lendingEventStructs))
+	for i, event := range lendingEventStructs {
+		pkg := *c.lendingPortalPackageId
+		if event.core {
+			pkg = lendingCore
+		}
+		eventTypes[i] = c.eventType(pkg, event.module, event.name)
+	}
+	return eventTypes, nil
+}
+
+// lendingPortalEventTypes return the lending portal events of LendingEventTypes, they do not need
+// the lending core package
+func (c *Contract) lendingPortalEventTypes() []string {
+	var eventTypes []string
+	for _, event := range lendingEventStructs {
+		if !event.core {
+			eventTypes = append(eventTypes, c.eventType(*c.lendingPortalPackageId, event.module, event.name))
+		}
+	}
+	return eventTypes
+}
+
+// eventType return module::name of pkg, the package is its type origin if pkg is resolved
+func (c *Contract) eventType(pkg sui_types.ObjectID, module, name string) string {
+	if info, ok := c.PackageInfo(pkg); ok {
+		pkg = info.TypeOrigin(module, name)
+	}
+	return pkg.String() + "::" + module + "::" + name
+}
+
+// LendingCorePackageId return the package defining the type of the Storage object
+func (c *Contract) LendingCorePackageId(ctx context.Context) (sui_types.ObjectID, error) {
+	c.cacheLock.Lock()
+	cached := c.lendingCorePackage
+	c.cacheLock.Unlock()
+	if cached != nil {
+		return *cached, nil
+	}
+
+	object, err := c.client.GetObject(ctx, *c.storage, &types.SuiObjectDataOptions{ShowType: true})
+	if err != nil {
+		return sui_types.ObjectID{}, err
+	}
+	if object.Data == nil || object.Data.Type == nil {
+		return sui_types.ObjectID{}, fmt.Errorf("storage %s not found", c.storage)
+	}
+	tag, err := parseTypeTag(*object.Data.Type)
+	if err != nil || tag.Struct == nil {
+		return sui_types.ObjectID{}, fmt.Errorf("storage %s has type %s", c.storage, *object.Data.Type)
+	}
+
+	c.cacheLock.Lock()
+	c.lendingCorePackage = &tag.Struct.Address
+	c.cacheLock.Unlock()
+	return tag.Struct.Address, nil
+}
+
+// NewLendingEventSubscriber return a subscriber of the LendingEventTypes of c if options has no
+// EventTypes, call ResolvePackages first if a package may be upgraded
+func (c *Contract) NewLendingEventSubscriber(ctx context.Context, options EventSubscriberOptions) (*EventSubscriber, error) {
+	if len(options.EventTypes) == 0 {
+		eventTypes, err := c.LendingEventTypes(ctx)
+		if err != nil {
+			return nil, err
+		}
+		options.EventTypes = eventTypes
+	}
+	return NewEventSubscriber(c.client, options), nil
+}
+
+func (e *EventDecodeError) Error() string {
+	return fmt.Sprintf("parse event %s#%d: %v", e.Event.Id.TxDigest, e.Event.Id.EventSeq.Uint64(), e.Err)
+}
+
+func (e *EventDecodeError) Unwrap() error {
+	return e.Err
+}
+
+func NewMemoryCursorStore() *MemoryCursorStore {
+	return &MemoryCursorStore{cursors: make(map[string]types.EventId)}
+}
+
+func (s *MemoryCursorStore) LoadCursor(eventType string) (*types.EventId, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	cursor, ok := s.cursors[eventType]
+	if !ok {
+		return nil, nil
+	}
+	return &cursor, nil
+}
+
+func (s *MemoryCursorStore) SaveCursor(eventType string, cursor types.EventId) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.cursors[eventType] = cursor
+	return nil
+}
+
+func NewFileCursorStore(path string) *FileCursorStore {
+	return &FileCursorStore{path: path}
+}
+
+func (s *FileCursorStore) LoadCursor(eventType string) (*types.EventId, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	cursors, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	cursor, ok := cursors[eventType]
+	if !ok {
+		return nil, nil
+	}
+	return &cursor, nil
+}
+
+func (s *FileCursorStore) SaveCursor(eventType string, cursor types.EventId) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	cursors, err := s.load()
+	if err != nil {
+		return err
+	}
+	cursors[eventType] = cursor
+	data, err := json.MarshalIndent(cursors, "", "  ")
+	if err != nil {
+		return err
+	}
+	// write then rename, a crash never leaves a half written file
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err = tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
+
+func (s *FileCursorStore) load() (map[string]types.EventId, error) {
+	cursors := make(map[string]types.EventId)
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cursors, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(data, &cursors); err != nil {
+		return nil, fmt.Errorf("load cursors %s: %w", s.path, err)
+	}
+	return cursors, nil
+}
+
+func NewEventSubscriber(client *client.Client, options EventSubscriberOptions) *EventSubscriber {
+	if options.Store == nil {
+		options.Store = NewMemoryCursorStore()
+	}
+	if options.PollInterval == 0 {
+		options.PollInterval = defaultPollInterval
+	}
+	if options.PageLimit == 0 {
+		options.PageLimit = defaultEventPageLimit
+	}
+	if options.MaxRetryInterval == 0 {
+		options.MaxRetryInterval = defaultMaxRetryInterval
+	}
+	return &EventSubscriber{client: client, options: options}
+}
+
+// Subscribe run the subscriber in background until ctx is done or an error happen. Both channels
+// are closed on return, the error, if any, is sent to the error channel unless ctx is done. Skipped events are
+// sent to the error channel as *EventDecodeError without stopping, read it along with the events
+func (s *EventSubscriber) Subscribe(ctx context.Context) (<-chan SubscribedEvent, <-chan error) {
+	events := make(chan SubscribedEvent)
+	errs := make(chan error, 1)
+	report := func(err *EventDecodeError) {
+		select {
+		case errs <- err:
+		case <-ctx.Done():
+		}
+	}
+	go func() {
+		defer close(errs)
+		defer close(events)
+		if err := s.run(ctx, events, report); err != nil && !errors.Is(err, context.Canceled) {
+			select {
+			case errs <- err:
+			case <-ctx.Done():
+			}
+		}
+	}()
+	return events, errs
+}
+
+// Run poll every event type from its saved cursor and send decoded events to out. A cursor is saved
+// only after its event has been received from out, so a restart resume after the last delivered event
+func (s *EventSubscriber) Run(ctx context.Context, out chan<- SubscribedEvent) error {
+	return s.run(ctx, out, s.options.OnDecodeError)
+}
+
+func (s *EventSubscriber) run(ctx context.Context, out chan<- SubscribedEvent, report func(err *EventDecodeError)) error {
+	if len(s.options.EventTypes) == 0 {
+		return errors.New("no event type to subscribe")
+	}
+	cursors := make(map[string]*types.EventId, len(s.options.EventTypes))
+	for _, eventType := range s.options.EventTypes {
+		cursor, err := s.options.Store.LoadCursor(eventType)
+		if err != nil {
+			return err
+		}
+		cursors[eventType] = cursor
+	}
+
+	for {
+		idle := true
+		for _, eventType := range s.options.EventTypes {
+			more, err := s.poll(ctx, eventType, cursors, out, report)
+			if err != nil {
+				return err
+			}
+			idle = idle && !more
+		}
+		if !idle {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(s.options.PollInterval):
+		}
+	}
+}
+
+// poll deliver one page of eventType, it report whether more events are waiting
+func (s *EventSubscriber) poll(ctx context.Context, eventType string, cursors map[string]*types.EventId, out chan<- SubscribedEvent, report func(err *EventDecodeError)) (bool, error) {
+	page, err := s.queryEvents(ctx, eventType, cursors[eventType])
+	if err != nil {
+		return false, err
+	}
+	for _, raw := range page.Data {
+		event, err := s.decode(raw)
+		if err != nil {
+			decodeErr := &EventDecodeError{EventType: eventType, Event: raw, Err: err}
+			if s.options.StopOnDecodeError {
+				return false, decodeErr
+			}
+			if report != nil {
+				report(decodeErr)
+			}
+		} else {
+			select {
+			case out <- SubscribedEvent{EventType: eventType, Event: event, Raw: raw}:
+			case <-ctx.Done():
+				return false, ctx.Err()
+			}
+		}
+		id := raw.Id
+		if err = s.options.Store.SaveCursor(eventType, id); err != nil {
+			return false, err
+		}
+		cursors[eventType] = &id
+	}
+	return page.HasNextPage && len(page.Data) > 0, nil
+}
+
+// queryEvents query one page after cursor, failures are retried with backoff, see RetryLimit
+func (s *EventSubscriber) queryEvents(ctx context.Context, eventType string, cursor *types.EventId) (*types.EventPage, error) {
+	moveEventType := eventType
+	limit := s.options.PageLimit
+	wait := s.options.PollInterval
+	for failures := 1; ; failures++ {
+		page, err := s.client.QueryEvents(ctx, types.EventFilter{MoveEventType: &moveEventType}, cursor, &limit, false)
+		if err == nil {
+			return page, nil
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if s.options.RetryLimit > 0 && failures >= s.options.RetryLimit {
+			return nil, fmt.Errorf("query %s: %w", eventType, err)
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(wait):
+		}
+		if wait *= 2; wait > s.options.MaxRetryInterval {
+			wait = s.options.MaxRetryInterval
+		}
+	}
+}
+
+func (s *EventSubscriber) decode(event types.SuiEvent) (any, error) {
+	if s.options.Registry != nil {
+		return s.options.Registry.Decode(event)
+	}
+	return parseLendingEvent(event)
+}
+
+// parseLendingEvent dispatch event to the Parse function of its struct name
+func parseLendingEvent(event types.SuiEvent) (any, error) {
+	switch name := eventStructName(event.Type); name {
+	case LocalLendingEventName:
+		return ParseLocalLendingEvent(event)
+	case LendingPortalEventName:
+		return ParseLendingPortalEvent(event)
+	case LendingCoreEventName:
+		return ParseLendingCoreEvent(event)
+	case LendingCoreExecuteEventName:
+		return ParseLendingCoreExecuteEvent(event)
+	default:
+		return nil, fmt.Errorf("unknown lending event %s", name)
+	}
+}
@@ -0,0 +1,140 @@
+package gosuilending
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/coming-chat/go-sui/v2/client"
+	"github.com/coming-chat/go-sui/v2/sui_types"
+	"github.com/coming-chat/go-sui/v2/types"
+)
+
+func Test_FileCursorStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cursors.json")
+	store := NewFileCursorStore(path)
+	cursor, err := store.LoadCursor("a")
+	AssertNil(err)
+	if cursor != nil {
+		t.Fatalf("expect no cursor, got %v", cursor)
+	}
+
+	digest, err := sui_types.NewDigest("8wdDDRaQrHTuwVRhXpERgW3VXUSddcAfkKjkoe3m7Upb")
+	AssertNil(err)
+	AssertNil(store.SaveCursor("a", types.EventId{TxDigest: *digest, EventSeq: types.NewSafeSuiBigInt(uint64(3))}))
+	AssertNil(store.SaveCursor("b", types.EventId{TxDigest: *digest, EventSeq: types.NewSafeSuiBigInt(uint64(1))}))
+
+	cursor, err = NewFileCursorStore(path).LoadCursor("a")
+	AssertNil(err)
+	if cursor == nil || cursor.EventSeq.Uint64() != 3 || cursor.TxDigest.String() != digest.String() {
+		t.Errorf("LoadCursor() = %v", cursor)
+	}
+}
+
+func Test_parseLendingEvent(t *testing.T) {
+	event := types.SuiEvent{
+		Type: "0x1::logic::LendingCoreExecuteEvent",
+		ParsedJson: map[string]interface{}{
+			"user_id": "1", "violator_id": "0", "pool_id": float64(2), "call_type": float64(CallTypeSupply), "amount": "100",
+		},
+	}
+	parsed, err := parseLendingEvent(event)
+	AssertNil(err)
+	if executeEvent, ok := parsed.(*LendingCoreExecuteEvent); !ok || executeEvent.PoolId != 2 {
+		t.Errorf("parseLendingEvent() = %#v", parsed)
+	}
+
+	event.Type = "0x1::lending::Unknown"
+	if _, err = parseLendingEvent(event); err == nil {
+		t.Errorf("parseLendingEvent() should fail on unknown event")
+	}
+}
+
+func Test_EventSubscriber_retryAndSkip(t *testing.T) {
+	digest := "8wdDDRaQrHTuwVRhXpERgW3VXUSddcAfkKjkoe3m7Upb"
+	pages := []string{
+		"", // transient failure
+		`{"data": [
+			{"id": {"txDigest": "` + digest + `", "eventSeq": "0"}, "packageId": "0x1", "transactionModule": "logic", "sender": "0x1",
+			 "type": "0x1::logic::LendingCoreExecuteEvent", "parsedJson": {"user_id": "x"}, "bcs": ""},
+			{"id": {"txDigest": "` + digest + `", "eventSeq": "1"}, "packageId": "0x1", "transactionModule": "logic", "sender": "0x1",
+			 "type": "0x1::logic::LendingCoreExecuteEvent", "bcs": "",
+			 "parsedJson": {"user_id": "1", "violator_id": "0", "pool_id": 2, "call_type": 0, "amount": "100"}}
+		], "hasNextPage": false}`,
+	}
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Id any `json:"id"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		call := int(atomic.AddInt32(&calls, 1)) - 1
+		if call == 0 {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		result := `{"data": [], "hasNextPage": false}`
+		if call < len(pages) {
+			result = pages[call]
+		}
+		id, _ := json.Marshal(req.Id)
+		_, _ = w.Write([]byte(`{"jsonrpc": "2.0", "id": ` + string(id) + `, "result": ` + result + `}`))
+	}))
+	defer server.Close()
+	client, err := client.Dial(server.URL)
+	AssertNil(err)
+
+	store := NewMemoryCursorStore()
+	subscriber := NewEventSubscriber(client, EventSubscriberOptions{
+		EventTypes:   []string{"0x1::logic::LendingCoreExecuteEvent"},
+		Store:        store,
+		PollInterval: time.Millisecond,
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	events, errs := subscriber.Subscribe(ctx)
+
+	var decodeErr *EventDecodeError
+	select {
+	case err := <-errs:
+		if !errors.As(err, &decodeErr) || decodeErr.Event.Id.EventSeq.Uint64() != 0 {
+			t.Fatalf("expect the first event skipped, got %v", err)
+		}
+	case event := <-events:
+		t.Fatalf("expect a decode error first, got %+v", event)
+	}
+	event := <-events
+	if executeEvent, ok := event.Event.(*LendingCoreExecuteEvent); !ok || executeEvent.PoolId != 2 {
+		t.Fatalf("unexpected event %+v", event)
+	}
+	cancel()
+	for range events {
+	}
+	for range errs {
+	}
+	cursor, err := store.LoadCursor("0x1::logic::LendingCoreExecuteEvent")
+	AssertNil(err)
+	if cursor == nil || cursor.EventSeq.Uint64() != 1 {
+		t.Errorf("cursor = %v", cursor)
+	}
+}
+
+func Test_EventSubscriber_closeOnError(t *testing.T) {
+	// no event type, run return at once
+	events, errs := NewEventSubscriber(nil, EventSubscriberOptions{}).Subscribe(context.Background())
+	if err := <-errs; err == nil {
+		t.Fatal("expect an error without event types")
+	}
+	if _, ok := <-errs; ok {
+		t.Error("errs is not closed")
+	}
+	if _, ok := <-events; ok {
+		t.Error("events is not closed")
+	}
+}
@@ -0,0 +1,243 @@
+package gosuilending
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/coming-chat/go-sui/v2/client"
+	"github.com/coming-chat/go-sui/v2/lib"
+	"github.com/coming-chat/go-sui/v2/sui_types"
+	"github.com/coming-chat/go-sui/v2/types"
+	"golang.org/x/crypto/blake2b"
+)
+
+const (
+	defaultPollInterval    = time.Second
+	defaultFinalityTimeout = time.Minute
+)
+
+// Signer signs transaction bytes built by Contract for the address it owns
+type Signer interface {
+	Address() sui_types.SuiAddress
+	SignTransaction(txBytes lib.Base64Data) (sui_types.Signature, error)
+}
+
+type keyPairSigner struct {
+	keyPair sui_types.SuiKeyPair
+	address sui_types.SuiAddress
+}
+
+// NewKeyPairSigner return a Signer backed by a local key pair
+func NewKeyPairSigner(keyPair sui_types.SuiKeyPair) (Signer, error) {
+	if len(keyPair.PublicKey()) == 0 {
+		return nil, errors.New("unsupported key pair scheme")
+	}
+	data := append([]byte{keyPair.Flag()}, keyPair.PublicKey()...)
+	addrBytes := blake2b.Sum256(data)
+	address, err := sui_types.NewAddressFromHex(hex.EncodeToString(addrBytes[:]))
+	if err != nil {
+		return nil, err
+	}
+	return &keyPairSigner{keyPair: keyPair, address: *address}, nil
+}
+
+// NewKeystoreSigner return a Signer from a sui keystore entry, base64(flag || private key)
+func NewKeystoreSigner(keystore string) (Signer, error) {
+	ksByte, err := base64.StdEncoding.DecodeString(keystore)
+	if err != nil {
+		return nil, err
+	}
+	if len(ksByte) == 0 {
+		return nil, errors.New("empty keystore")
+	}
+	scheme, err := sui_types.NewSignatureScheme(ksByte[0])
+	if err != nil {
+		return nil, err
+	}
+	return NewKeyPairSigner(sui_types.NewSuiKeyPair(scheme, ksByte[1:]))
+}
+
+func (s *keyPairSigner) Address() sui_types.SuiAddress {
+	return s.address
+}
+
+func (s *keyPairSigner) SignTransaction(txBytes lib.Base64Data) (sui_types.Signature, error) {
+	return sui_types.NewSignatureSecure(sui_types.NewIntentMessage(sui_types.DefaultIntent(), txBytes), &s.keyPair)
+}
+
+type ExecuteOptions struct {
+	PollInterval    time.Duration // interval of polling the transaction until finality
+	FinalityTimeout time.Duration // max wait for the transaction to be included in a checkpoint
+	// full types of the lending portal events to decode, see Contract.LendingEventTypes. The
+	// LendingEventTypes of LendingPortalPackageId if empty, lending events are only decoded if
+	// one is set, see Contract.NewExecutor
+	LendingEventTypes []string
+	// package defining the lending events, PackageInfo.Original if the package is upgraded
+	LendingPortalPackageId string
+}
+
+// TransactionReceipt is the result of an executed transaction, with lending events already decoded
+type TransactionReceipt struct {
+	Digest     string
+	Status     string // types.ExecutionStatusSuccess or types.ExecutionStatusFailure
+	Error      string // move abort or execution error if failed
+	GasUsed    types.GasCostSummary
+	GasFee     int64 // computation + storage - rebate, in MIST
+	Checkpoint uint64
+
+	Events              []types.SuiEvent
+	LocalLendingEvents  []*LocalLendingEvent
+	LendingPortalEvents []*LendingPortalEvent
+	// lending events which could not be decoded. The transaction is executed anyway, do not
+	// submit it again
+	ParseErrors []error
+}
+
+func (r *TransactionReceipt) IsSuccess() bool {
+	return r.Status == types.ExecutionStatusSuccess
+}
+
+// Executor sign, submit and wait transactions built by Contract
+type Executor struct {
+	client  *client.Client
+	options ExecuteOptions
+}
+
+func NewExecutor(client *client.Client) *Executor {
+	return NewExecutorWithOptions(client, ExecuteOptions{})
+}
+
+func NewExecutorWithOptions(client *client.Client, options ExecuteOptions) *Executor {
+	if options.PollInterval <= 0 {
+		options.PollInterval = defaultPollInterval
+	}
+	if options.FinalityTimeout <= 0 {
+		options.FinalityTimeout = defaultFinalityTimeout
+	}
+	return &Executor{client: client, options: options}
+}
+
+// NewExecutor return an executor decoding the lending events of the lending portal package of c,
+// from the packages defining them once ResolvePackages is called
+func (c *Contract) NewExecutor(options ExecuteOptions) *Executor {
+	if len(options.LendingEventTypes) == 0 && options.LendingPortalPackageId == "" {
+		options.LendingEventTypes = c.lendingPortalEventTypes()
+	}
+	return NewExecutorWithOptions(c.client, options)
+}
+
+// Execute sign tx by signer, submit it and wait until it is checkpointed.
+// A transaction failed on chain is not an error, check TransactionReceipt.IsSuccess. Once
+// submitted, the receipt is returned even if some events can not be decoded, see ParseErrors
+func (e *Executor) Execute(ctx context.Context, signer Signer, tx *types.TransactionBytes) (*TransactionReceipt, error) {
+	if tx == nil {
+		return nil, errors.New("nil transaction")
+	}
+	signature, err := signer.SignTransaction(tx.TxBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	options := types.SuiTransactionBlockResponseOptions{
+		ShowEffects: true,
+		ShowEvents:  true,
+	}
+	resp, err := e.client.ExecuteTransactionBlock(ctx, tx.TxBytes, []any{signature}, &options, types.TxnRequestTypeWaitForLocalExecution)
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Errors) > 0 {
+		return nil, errors.New(strings.Join(resp.Errors, "; "))
+	}
+
+	if resp.Checkpoint == nil || resp.Effects == nil {
+		if resp, err = e.waitForFinality(ctx, resp.Digest, options); err != nil {
+			return nil, err
+		}
+	}
+	return newTransactionReceipt(resp, e.lendingEventTypes())
+}
+
+// lendingEventTypes return the event types decoded into receipts, see ExecuteOptions
+func (e *Executor) lendingEventTypes() []string {
+	if len(e.options.LendingEventTypes) > 0 || e.options.LendingPortalPackageId == "" {
+		return e.options.LendingEventTypes
+	}
+	return []string{
+		lendingEventType(e.options.LendingPortalPackageId, LocalLendingEventName),
+		lendingEventType(e.options.LendingPortalPackageId, LendingPortalEventName),
+	}
+}
+
+func (e *Executor) waitForFinality(ctx context.Context, digest sui_types.TransactionDigest, options types.SuiTransactionBlockResponseOptions) (*types.SuiTransactionBlockResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, e.options.FinalityTimeout)
+	defer cancel()
+
+	ticker := time.NewTicker(e.options.PollInterval)
+	defer ticker.Stop()
+	for {
+		resp, err := e.client.GetTransactionBlock(ctx, digest, options)
+		if err == nil && resp.Checkpoint != nil && resp.Effects != nil {
+			return resp, nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("wait transaction %s finality: %w", digest, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// newTransactionReceipt decode the events of eventTypes, it only fail on a response without
+// effects, event decoding errors are kept in ParseErrors
+func newTransactionReceipt(resp *types.SuiTransactionBlockResponse, eventTypes []string) (*TransactionReceipt, error) {
+	if resp.Effects == nil || resp.Effects.Data.V1 == nil {
+		return nil, errors.New("transaction response without effects")
+	}
+	effects := resp.Effects.Data
+	receipt := &TransactionReceipt{
+		Digest:  resp.Digest.String(),
+		Status:  effects.V1.Status.Status,
+		Error:   effects.V1.Status.Error,
+		GasUsed: effects.V1.GasUsed,
+		GasFee:  effects.GasFee(),
+		Events:  resp.Events,
+	}
+	if resp.Checkpoint != nil {
+		receipt.Checkpoint = resp.Checkpoint.Uint64()
+	}
+	decoded := make(map[string]bool, len(eventTypes))
+	for _, eventType := range eventTypes {
+		if normalized, err := normalizeMoveType(eventType); err == nil {
+			decoded[normalized] = true
+		}
+	}
+
+	for _, event := range resp.Events {
+		if normalized, err := normalizeMoveType(event.Type); err != nil || !decoded[normalized] {
+			continue
+		}
+		switch eventStructName(event.Type) {
+		case LocalLendingEventName:
+			localEvent, err := ParseLocalLendingEvent(event)
+			if err != nil {
+				receipt.ParseErrors = append(receipt.ParseErrors, fmt.Errorf("event %d: %w", event.Id.EventSeq.Uint64(), err))
+				continue
+			}
+			receipt.LocalLendingEvents = append(receipt.LocalLendingEvents, localEvent)
+		case LendingPortalEventName:
+			portalEvent, err := ParseLendingPortalEvent(event)
+			if err != nil {
+				receipt.ParseErrors = append(receipt.ParseErrors, fmt.Errorf("event %d: %w", event.Id.EventSeq.Uint64(), err))
+				continue
+			}
+			receipt.LendingPortalEvents = append(receipt.LendingPortalEvents, portalEvent)
+		}
+	}
+	return receipt, nil
+}
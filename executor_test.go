@@ -0,0 +1,64 @@
+package gosuilending
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/coming-chat/go-sui/v2/types"
+)
+
+func Test_newTransactionReceipt(t *testing.T) {
+	var resp types.SuiTransactionBlockResponse
+	AssertNil(json.Unmarshal([]byte(`{
+		"digest": "3Ruvp6RvGyETpaGLiAjGo6mYoqjvXLTBqqGAL5EahZxp",
+		"effects": {
+			"messageVersion": "v1",
+			"status": {"status": "success"},
+			"executedEpoch": "1",
+			"gasUsed": {"computationCost": "1000", "storageCost": "500", "storageRebate": "200", "nonRefundableStorageFee": "0"},
+			"transactionDigest": "3Ruvp6RvGyETpaGLiAjGo6mYoqjvXLTBqqGAL5EahZxp",
+			"gasObject": {"owner": {"AddressOwner": "0x1"}, "reference": {"objectId": "0x5", "version": 1, "digest": "3Ruvp6RvGyETpaGLiAjGo6mYoqjvXLTBqqGAL5EahZxp"}},
+			"dependencies": []
+		},
+		"checkpoint": "7"
+	}`), &resp))
+	local := map[string]interface{}{
+		"nonce": "1", "amount": "100", "sender": "0x1", "call_type": float64(CallTypeSupply), "dola_pool_address": []interface{}{float64(1)},
+	}
+	timestamp := types.NewSafeSuiBigInt(uint64(1_690_000_000_000))
+	resp.Events = []types.SuiEvent{
+		{Type: "0x1::lending::LocalLendingEvent", ParsedJson: local, TimestampMs: &timestamp},
+		// same struct from another package
+		{Type: "0x3::lending::LocalLendingEvent", ParsedJson: local, TimestampMs: &timestamp},
+		// undecodable, the transaction is executed anyway
+		{Type: "0x1::lending::LendingPortalEvent", ParsedJson: map[string]interface{}{"nonce": "x"}, TimestampMs: &timestamp},
+	}
+
+	eventTypes := []string{"0x1::lending::LocalLendingEvent", "0x1::lending::LendingPortalEvent"}
+	if got := NewExecutorWithOptions(nil, ExecuteOptions{LendingPortalPackageId: "0x1"}).lendingEventTypes(); fmt.Sprint(got) != fmt.Sprint(eventTypes) {
+		t.Errorf("lendingEventTypes() = %v", got)
+	}
+	receipt, err := newTransactionReceipt(&resp, eventTypes)
+	AssertNil(err)
+	if !receipt.IsSuccess() || receipt.Checkpoint != 7 || receipt.GasFee != 1300 {
+		t.Errorf("receipt = %+v", receipt)
+	}
+	if len(receipt.LocalLendingEvents) != 1 || receipt.LocalLendingEvents[0].Amount != 100 {
+		t.Errorf("LocalLendingEvents = %+v", receipt.LocalLendingEvents)
+	}
+	if len(receipt.LendingPortalEvents) != 0 || len(receipt.ParseErrors) != 1 {
+		t.Errorf("LendingPortalEvents = %+v, ParseErrors = %v", receipt.LendingPortalEvents, receipt.ParseErrors)
+	}
+
+	receipt, err = newTransactionReceipt(&resp, nil)
+	AssertNil(err)
+	if len(receipt.Events) != 3 || len(receipt.LocalLendingEvents) != 0 || len(receipt.ParseErrors) != 0 {
+		t.Errorf("receipt without lending package = %+v", receipt)
+	}
+
+	resp.Effects = nil
+	if _, err = newTransactionReceipt(&resp, eventTypes); err == nil {
+		t.Errorf("newTransactionReceipt() should fail without effects")
+	}
+}
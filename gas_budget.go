@@ -0,0 +1,167 @@
+package gosuilending
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/coming-chat/go-sui/v2/sui_types"
+	"github.com/coming-chat/go-sui/v2/types"
+)
+
+const (
+	// DefaultGasSafetyMargin is used when CallOptions.GasSafetyMargin is 0, 20 -> 20%
+	DefaultGasSafetyMargin = 20
+	// DefaultDryRunGasBudget is the budget, or the budget of the dry run with AutoGasBudget, when
+	// CallOptions.GasBudget is 0
+	DefaultDryRunGasBudget = 50_000_000
+	// minGasUnits is the least gas units a transaction is charged, budget below minGasUnits*gasPrice is rejected
+	minGasUnits = 1000
+)
+
+// buildCalls pick the gas coin and coins left empty by the caller, then build calls as one
+// transaction. With callOptions.AutoGasBudget the transaction is dry run first and rebuilt
+// with the gas it used
+func (c *Contract) buildCalls(ctx context.Context, signer sui_types.SuiAddress, calls []moveCall, callOptions CallOptions) (*types.TransactionBytes, error) {
+	if callOptions.GasBudget == 0 {
+		callOptions.GasBudget = DefaultDryRunGasBudget
+	}
+	var gasBalance uint64
+	if callOptions.Gas == nil {
+		gas, err := c.selectGasCoin(ctx, signer)
+		if err != nil {
+			return nil, err
+		}
+		callOptions.Gas = &gas.CoinObjectId
+		gasBalance = gas.Balance.Uint64()
+	}
+	calls, err := c.selectCallCoins(ctx, signer, calls, callOptions)
+	if err != nil {
+		return nil, err
+	}
+	if gasBalance > 0 {
+		// the budget can not be more than the gas coin left after the SUI split from it
+		if callOptions.GasBudget, err = capGasBudget(callOptions.GasBudget, gasBalance, calls); err != nil {
+			return nil, err
+		}
+	}
+	if !callOptions.AutoGasBudget {
+		return c.buildTransaction(ctx, signer, calls, callOptions)
+	}
+
+	tx, err := c.buildTransaction(ctx, signer, calls, callOptions)
+	if err != nil {
+		return nil, err
+	}
+	if callOptions.GasBudget, err = c.estimateGasBudget(ctx, tx, callOptions); err != nil {
+		return nil, err
+	}
+	if gasBalance > 0 {
+		if left := gasBalance - splitGasAmount(calls); callOptions.GasBudget > left {
+			return nil, fmt.Errorf("%w: gas budget %d exceeds gas coin left %d", ErrInsufficientBalance, callOptions.GasBudget, left)
+		}
+	}
+	return c.buildTransaction(ctx, signer, calls, callOptions)
+}
+
+// capGasBudget cap budget at the gas coin balance minus the amount calls split from the gas coin
+func capGasBudget(budget, gasBalance uint64, calls []moveCall) (uint64, error) {
+	split := splitGasAmount(calls)
+	if split >= gasBalance {
+		return 0, fmt.Errorf("%w: split %d from gas coin of %d", ErrInsufficientBalance, split, gasBalance)
+	}
+	if left := gasBalance - split; budget > left {
+		return left, nil
+	}
+	return budget, nil
+}
+
+// splitGasAmount return the total amount calls split from the gas coin
+func splitGasAmount(calls []moveCall) uint64 {
+	var amount uint64
+	for _, call := range calls {
+		for _, arg := range call.args {
+			if split, ok := arg.(splitGasCoin); ok {
+				amount += split.amount
+			}
+		}
+	}
+	return amount
+}
+
+func (c *Contract) buildTransaction(ctx context.Context, signer sui_types.SuiAddress, calls []moveCall, callOptions CallOptions) (*types.TransactionBytes, error) {
+	if len(calls) == 1 && !calls[0].needProgrammable() {
+		call := c.resolveCall(calls[0])
+		return c.client.MoveCall(ctx, signer, *call.packageId, call.module, call.function, call.typeArgs, call.args, callOptions.Gas, types.NewSafeSuiBigInt(callOptions.GasBudget))
+	}
+	pt, err := c.programmableTransaction(ctx, calls)
+	if err != nil {
+		return nil, err
+	}
+	return c.buildProgrammableTransaction(ctx, signer, pt, callOptions)
+}
+
+// estimateGasBudget dry run tx and return its computation and storage cost plus the safety margin
+func (c *Contract) estimateGasBudget(ctx context.Context, tx *types.TransactionBytes, callOptions CallOptions) (uint64, error) {
+	result, err := c.client.DryRunTransaction(ctx, tx.TxBytes)
+	if err != nil {
+		return 0, err
+	}
+	effects := result.Effects.Data.V1
+	if effects == nil {
+		return 0, errors.New("dry run without effects")
+	}
+	if !result.Effects.Data.IsSuccess() {
+		return 0, fmt.Errorf("dry run failed: %s", effects.Status.Error)
+	}
+
+	gasPrice, err := c.client.GetReferenceGasPrice(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return computeGasBudget(effects.GasUsed, gasPrice.Uint64(), callOptions)
+}
+
+func computeGasBudget(gasUsed types.GasCostSummary, gasPrice uint64, callOptions CallOptions) (uint64, error) {
+	margin := callOptions.GasSafetyMargin
+	if margin == 0 {
+		margin = DefaultGasSafetyMargin
+	}
+	used := gasUsed.ComputationCost.Uint64() + gasUsed.StorageCost.Uint64()
+	budget := used * (100 + margin) / 100
+	if minBudget := gasPrice * minGasUnits; budget < minBudget {
+		budget = minBudget
+	}
+	if callOptions.MaxGasBudget > 0 && budget > callOptions.MaxGasBudget {
+		return 0, fmt.Errorf("gas budget %d exceeds max gas budget %d", budget, callOptions.MaxGasBudget)
+	}
+	return budget, nil
+}
+
+// selectGasCoin return the biggest SUI coin of owner
+func (c *Contract) selectGasCoin(ctx context.Context, owner sui_types.SuiAddress) (*types.Coin, error) {
+	coinType := suiCoinType
+	var (
+		gas    *types.Coin
+		cursor *sui_types.ObjectID
+	)
+	for {
+		page, err := c.client.GetCoins(ctx, owner, &coinType, cursor, coinPageLimit)
+		if err != nil {
+			return nil, err
+		}
+		for i, coin := range page.Data {
+			if coin.LockedUntilEpoch == nil && (gas == nil || coin.Balance.Uint64() > gas.Balance.Uint64()) {
+				gas = &page.Data[i]
+			}
+		}
+		if !page.HasNextPage || page.NextCursor == nil {
+			break
+		}
+		cursor = page.NextCursor
+	}
+	if gas == nil {
+		return nil, fmt.Errorf("%w: no SUI coin for gas", ErrInsufficientBalance)
+	}
+	return gas, nil
+}
@@ -0,0 +1,68 @@
+package gosuilending
+
+import (
+	"testing"
+
+	"github.com/coming-chat/go-sui/v2/types"
+)
+
+func Test_computeGasBudget(t *testing.T) {
+	gasUsed := types.GasCostSummary{
+		ComputationCost: types.NewSafeSuiBigInt(uint64(1_000_000)),
+		StorageCost:     types.NewSafeSuiBigInt(uint64(9_000_000)),
+		StorageRebate:   types.NewSafeSuiBigInt(uint64(5_000_000)),
+	}
+	tests := []struct {
+		name        string
+		gasPrice    uint64
+		callOptions CallOptions
+		want        uint64
+		wantErr     bool
+	}{
+		{name: "default margin", gasPrice: 750, want: 12_000_000},
+		{name: "custom margin", gasPrice: 750, callOptions: CallOptions{GasSafetyMargin: 50}, want: 15_000_000},
+		{name: "reference gas price floor", gasPrice: 20_000, want: 20_000_000},
+		{name: "over max budget", gasPrice: 750, callOptions: CallOptions{MaxGasBudget: 11_000_000}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := computeGasBudget(gasUsed, tt.gasPrice, tt.callOptions)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("computeGasBudget() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("computeGasBudget() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_capGasBudget(t *testing.T) {
+	// supply 0.3 SUI split from a gas coin of 1 SUI
+	supply := moveCall{module: "lending", function: "supply", typeArgs: []string{suiCoinType}, args: []any{"0x1", splitGasCoin{amount: 300_000_000}, "300000000"}}
+	tests := []struct {
+		name    string
+		budget  uint64
+		calls   []moveCall
+		want    uint64
+		wantErr bool
+	}{
+		{name: "no split", budget: 2_000_000_000, want: 1_000_000_000},
+		{name: "budget under coin left", budget: DefaultDryRunGasBudget, calls: []moveCall{supply}, want: DefaultDryRunGasBudget},
+		{name: "sui supply from gas coin", budget: 2_000_000_000, calls: []moveCall{supply}, want: 700_000_000},
+		{name: "split whole gas coin", budget: DefaultDryRunGasBudget, calls: []moveCall{supply, supply, supply, supply}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := capGasBudget(tt.budget, 1_000_000_000, tt.calls)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("capGasBudget() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("capGasBudget() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -2,12 +2,17 @@ module github.com/omnibtc/go-sui-lending
 
 go 1.19
 
+require (
+	github.com/BurntSushi/toml v1.3.2
+	github.com/coming-chat/go-sui/v2 v2.0.0
+	github.com/fardream/go-bcs v0.2.1
+	github.com/shopspring/decimal v1.3.1
+	golang.org/x/crypto v0.0.0-20200622213623-75b288015ac9
+	gopkg.in/yaml.v3 v3.0.1
+)
+
 require (
 	github.com/btcsuite/btcutil v1.0.2 // indirect
-	github.com/coming-chat/go-sui/v2 v2.0.0 // indirect
-	github.com/fardream/go-bcs v0.2.1 // indirect
 	github.com/mitchellh/hashstructure/v2 v2.0.2 // indirect
-	github.com/shopspring/decimal v1.3.1 // indirect
-	golang.org/x/crypto v0.0.0-20200622213623-75b288015ac9 // indirect
 	golang.org/x/sys v0.8.0 // indirect
 )
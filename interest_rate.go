@@ -0,0 +1,156 @@
+package gosuilending
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+)
+
+const (
+	apyUnit       = 10000 // apys and utilization rate are basis points, 100 -> 100/10000=1.0%
+	secondsOfYear = 365 * 24 * 60 * 60
+)
+
+type (
+	// InterestRateModel is the two slopes rate curve of a reserve, all fields are 1e27 -> 1.0
+	//
+	//	borrow rate = base + min(u, optimal) * slope1 + max(u - optimal, 0) * slope2
+	//	supply rate = borrow rate * u * (1 - treasury factor)
+	InterestRateModel struct {
+		BaseBorrowRate     *big.Int
+		BorrowRateSlope1   *big.Int
+		BorrowRateSlope2   *big.Int
+		OptimalUtilization *big.Int
+		TreasuryFactor     *big.Int
+	}
+
+	// InterestRateModels is the model of every dola pool
+	InterestRateModels map[uint16]InterestRateModel
+
+	// InterestItem is the interest of one pool over the projection horizon, 1e8 amount and value
+	InterestItem struct {
+		DolaPoolId uint16
+		IsDebt     bool
+		Amount     *big.Int
+		Value      *big.Int
+	}
+
+	InterestProjection struct {
+		Horizon       time.Duration
+		SupplyValue   *big.Int // earned by collaterals
+		BorrowValue   *big.Int // owed by debts
+		NetValue      *big.Int // SupplyValue - BorrowValue, negative for a loss
+		InterestItems []InterestItem
+	}
+)
+
+// Utilization is debt / (debt + reserve), reserve is the liquidity left in the pool
+func Utilization(debt, reserve *big.Int) *big.Int {
+	total := new(big.Int).Add(debt, reserve)
+	if total.Sign() == 0 {
+		return big.NewInt(0)
+	}
+	return rayDiv(debt, total)
+}
+
+func (m InterestRateModel) BorrowRate(utilization *big.Int) *big.Int {
+	rate := new(big.Int).Set(m.BaseBorrowRate)
+	if utilization.Cmp(m.OptimalUtilization) <= 0 {
+		return rate.Add(rate, rayMul(utilization, m.BorrowRateSlope1))
+	}
+	rate.Add(rate, rayMul(m.OptimalUtilization, m.BorrowRateSlope1))
+	excess := new(big.Int).Sub(utilization, m.OptimalUtilization)
+	return rate.Add(rate, rayMul(excess, m.BorrowRateSlope2))
+}
+
+func (m InterestRateModel) SupplyRate(utilization *big.Int) *big.Int {
+	rate := rayMul(m.BorrowRate(utilization), utilization)
+	return rayMul(rate, new(big.Int).Sub(rayUnit, m.TreasuryFactor))
+}
+
+// Apply recompute the utilization and apys of reserve after a supply, withdraw, borrow or repay
+// of amount
+func (m InterestRateModel) Apply(reserve ReserveInfo, callType int, amount *big.Int) (ReserveInfo, error) {
+	after := reserve
+	after.Reserve = new(big.Int).Set(reserve.Reserve)
+	after.Debt = new(big.Int).Set(reserve.Debt)
+	switch callType {
+	case CallTypeSupply:
+		after.Reserve.Add(after.Reserve, amount)
+	case CallTypeWithdraw:
+		after.Reserve.Sub(after.Reserve, amount)
+	case CallTypeBorrow:
+		after.Reserve.Sub(after.Reserve, amount)
+		after.Debt.Add(after.Debt, amount)
+	case CallTypeRepay:
+		repaid := minBigInt(amount, after.Debt)
+		after.Debt.Sub(after.Debt, repaid)
+		after.Reserve.Add(after.Reserve, repaid)
+	default:
+		return reserve, fmt.Errorf("call type %d does not change the reserve", callType)
+	}
+	if after.Reserve.Sign() < 0 {
+		return reserve, fmt.Errorf("pool %d has not enough liquidity for %v", reserve.DolaPoolId, amount)
+	}
+	utilization := Utilization(after.Debt, after.Reserve)
+	after.UtilizationRate = rayToApy(utilization)
+	after.BorrowApy = rayToApy(m.BorrowRate(utilization))
+	after.SupplyApy = rayToApy(m.SupplyRate(utilization))
+	return after, nil
+}
+
+// Apply is InterestRateModel.Apply with the model of the reserve pool
+func (models InterestRateModels) Apply(reserve ReserveInfo, callType int, amount *big.Int) (ReserveInfo, error) {
+	model, ok := models[reserve.DolaPoolId]
+	if !ok {
+		return reserve, fmt.Errorf("interest rate model of pool %d not found", reserve.DolaPoolId)
+	}
+	return model.Apply(reserve, callType, amount)
+}
+
+// ProjectInterest project the interest of lendingInfo over horizon at the current apys of the
+// collateral and debt items. Interest is simple, not compounded within the horizon
+func ProjectInterest(lendingInfo *UserLendingInfo, horizon time.Duration) *InterestProjection {
+	projection := &InterestProjection{
+		Horizon:     horizon,
+		SupplyValue: big.NewInt(0),
+		BorrowValue: big.NewInt(0),
+	}
+	for _, item := range lendingInfo.CollateralInfos {
+		interest := InterestItem{
+			DolaPoolId: item.DolaPoolId,
+			Amount:     accrue(item.CollateralAmount, item.SupplyApy, horizon),
+			Value:      accrue(item.CollateralValue, item.SupplyApy, horizon),
+		}
+		projection.SupplyValue.Add(projection.SupplyValue, interest.Value)
+		projection.InterestItems = append(projection.InterestItems, interest)
+	}
+	for _, item := range lendingInfo.DebtInfos {
+		interest := InterestItem{
+			DolaPoolId: item.DolaPoolId,
+			IsDebt:     true,
+			Amount:     accrue(item.DebtAmount, item.BorrowApy, horizon),
+			Value:      accrue(item.DebtValue, item.BorrowApy, horizon),
+		}
+		projection.BorrowValue.Add(projection.BorrowValue, interest.Value)
+		projection.InterestItems = append(projection.InterestItems, interest)
+	}
+	projection.NetValue = new(big.Int).Sub(projection.SupplyValue, projection.BorrowValue)
+	return projection
+}
+
+// accrue is amount * apy * horizon / year
+func accrue(amount *big.Int, apy int, horizon time.Duration) *big.Int {
+	if amount == nil {
+		return big.NewInt(0)
+	}
+	result := new(big.Int).Mul(amount, big.NewInt(int64(apy)))
+	result.Mul(result, big.NewInt(int64(horizon/time.Second)))
+	return result.Quo(result, big.NewInt(apyUnit*secondsOfYear))
+}
+
+// rayToApy convert a 1e27 rate to basis points
+func rayToApy(rate *big.Int) int {
+	apy := new(big.Int).Mul(rate, big.NewInt(apyUnit))
+	return int(apy.Quo(apy, rayUnit).Int64())
+}
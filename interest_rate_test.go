@@ -0,0 +1,49 @@
+package gosuilending
+
+import (
+	"math/big"
+	"testing"
+	"time"
+)
+
+func Test_InterestRateModel(t *testing.T) {
+	ray := func(numerator, denominator int64) *big.Int {
+		r := new(big.Int).Mul(rayUnit, big.NewInt(numerator))
+		return r.Quo(r, big.NewInt(denominator))
+	}
+	model := InterestRateModel{
+		BaseBorrowRate:     ray(2, 100),
+		BorrowRateSlope1:   ray(1, 10),
+		BorrowRateSlope2:   ray(1, 1),
+		OptimalUtilization: ray(8, 10),
+		TreasuryFactor:     ray(1, 10),
+	}
+	reserve := ReserveInfo{DolaPoolId: 1, Reserve: big.NewInt(800), Debt: big.NewInt(200)}
+
+	after, err := model.Apply(reserve, CallTypeBorrow, big.NewInt(500))
+	AssertNil(err)
+	// u = 0.7, borrow = 0.02 + 0.7 * 0.1, supply = 0.09 * 0.7 * 0.9
+	if after.UtilizationRate != 7000 || after.BorrowApy != 900 || after.SupplyApy != 567 {
+		t.Errorf("Apply() = %+v", after)
+	}
+	// u = 0.9 is above the optimal utilization
+	after, err = InterestRateModels{1: model}.Apply(reserve, CallTypeBorrow, big.NewInt(700))
+	AssertNil(err)
+	if after.BorrowApy != 2000 || reserve.Debt.Int64() != 200 {
+		t.Errorf("Apply() = %+v, reserve %+v", after, reserve)
+	}
+	if _, err = model.Apply(reserve, CallTypeWithdraw, big.NewInt(801)); err == nil {
+		t.Errorf("Apply() should fail without liquidity")
+	}
+
+	projection := ProjectInterest(&UserLendingInfo{
+		CollateralInfos: []CollateralItem{{DolaPoolId: 0, CollateralAmount: big.NewInt(1e8), CollateralValue: big.NewInt(1000e8), SupplyApy: 500}},
+		DebtInfos:       []DebtItem{{DolaPoolId: 1, DebtAmount: big.NewInt(100e8), DebtValue: big.NewInt(100e8), BorrowApy: 1000}},
+	}, 365*24*time.Hour)
+	if projection.SupplyValue.Cmp(big.NewInt(50e8)) != 0 || projection.BorrowValue.Cmp(big.NewInt(10e8)) != 0 || projection.NetValue.Cmp(big.NewInt(40e8)) != 0 {
+		t.Errorf("ProjectInterest() = %+v", projection)
+	}
+	if projection.InterestItems[0].Amount.Cmp(big.NewInt(5e6)) != 0 {
+		t.Errorf("interest amount = %v", projection.InterestItems[0].Amount)
+	}
+}
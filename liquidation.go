@@ -0,0 +1,170 @@
+package gosuilending
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+)
+
+// rayUnit is the fixed point unit of health factor and coefficients, 1e27
+var rayUnit = new(big.Int).Exp(big.NewInt(10), big.NewInt(27), nil)
+
+type (
+	LiquidationQuoteArgs struct {
+		ViolatorId       string
+		DebtPoolId       uint16
+		CollateralPoolId uint16
+		// collateral bonus of liquidator, 500 -> 500/10000=5%. It is not exposed by the
+		// external interfaces, take it from the deployment of the lending core
+		Discount int
+		// max share of the debt repaid by one liquidation, 5000 -> 5000/10000=50%, 10000 to repay it all
+		CloseFactor int
+	}
+
+	LiquidationQuote struct {
+		ViolatorId   string
+		HealthFactor *big.Int // 1e27 -> 1.0
+		Liquidatable bool     // health factor below 1
+
+		DebtPoolId             uint16
+		CollateralPoolId       uint16
+		MaxRepayAmount         *big.Int // 100000000 -> 100000000/1e8 = 1
+		MaxRepayValue          *big.Int // 100000000 -> 100000000/1e8 = 1
+		CollateralSeizedAmount *big.Int // 100000000 -> 100000000/1e8 = 1
+		CollateralSeizedValue  *big.Int // 100000000 -> 100000000/1e8 = 1
+	}
+)
+
+// QuoteLiquidation compute the max debt a liquidator can repay for the violator and
+// the collateral it will seize, from the violator lending info and oracle prices
+func (q *QueryClient) QuoteLiquidation(ctx context.Context, quoteArgs LiquidationQuoteArgs) (*LiquidationQuote, error) {
+	if err := quoteArgs.check(); err != nil {
+		return nil, err
+	}
+	var (
+		lendingInfo *UserLendingInfo
+		prices      []DolaTokenPrice
+	)
+	err := q.NewBatch().
+		GetUserLendingInfo(quoteArgs.ViolatorId, &lendingInfo).
+		GetAllOraclePrice(&prices).
+		Execute(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return quoteLiquidation(quoteArgs, lendingInfo, prices)
+}
+
+// QuoteLiquidation is QueryClient.QuoteLiquidation
+func (c *Contract) QuoteLiquidation(ctx context.Context, quoteArgs LiquidationQuoteArgs) (*LiquidationQuote, error) {
+	return c.QueryClient().QuoteLiquidation(ctx, quoteArgs)
+}
+
+func (a LiquidationQuoteArgs) check() error {
+	if a.Discount <= 0 || a.Discount >= 10000 {
+		return fmt.Errorf("invalid liquidation discount %d", a.Discount)
+	}
+	if a.CloseFactor <= 0 || a.CloseFactor > 10000 {
+		return fmt.Errorf("invalid close factor %d", a.CloseFactor)
+	}
+	return nil
+}
+
+func quoteLiquidation(quoteArgs LiquidationQuoteArgs, lendingInfo *UserLendingInfo, prices []DolaTokenPrice) (*LiquidationQuote, error) {
+	if err := quoteArgs.check(); err != nil {
+		return nil, err
+	}
+	discount := quoteArgs.Discount
+
+	quote := &LiquidationQuote{
+		ViolatorId:             quoteArgs.ViolatorId,
+		HealthFactor:           lendingInfo.HealthFactor,
+		DebtPoolId:             quoteArgs.DebtPoolId,
+		CollateralPoolId:       quoteArgs.CollateralPoolId,
+		MaxRepayAmount:         big.NewInt(0),
+		MaxRepayValue:          big.NewInt(0),
+		CollateralSeizedAmount: big.NewInt(0),
+		CollateralSeizedValue:  big.NewInt(0),
+	}
+	quote.Liquidatable = lendingInfo.HealthFactor != nil && lendingInfo.HealthFactor.Cmp(rayUnit) < 0
+	if !quote.Liquidatable {
+		return quote, nil
+	}
+
+	var debt *DebtItem
+	for i := range lendingInfo.DebtInfos {
+		if lendingInfo.DebtInfos[i].DolaPoolId == quoteArgs.DebtPoolId {
+			debt = &lendingInfo.DebtInfos[i]
+		}
+	}
+	var collateral *CollateralItem
+	for i := range lendingInfo.CollateralInfos {
+		if lendingInfo.CollateralInfos[i].DolaPoolId == quoteArgs.CollateralPoolId {
+			collateral = &lendingInfo.CollateralInfos[i]
+		}
+	}
+	if debt == nil {
+		return nil, fmt.Errorf("user %s has no debt in pool %d", quoteArgs.ViolatorId, quoteArgs.DebtPoolId)
+	}
+	if collateral == nil {
+		return nil, fmt.Errorf("user %s has no collateral in pool %d", quoteArgs.ViolatorId, quoteArgs.CollateralPoolId)
+	}
+	debtPrice, err := findPrice(prices, quoteArgs.DebtPoolId)
+	if err != nil {
+		return nil, err
+	}
+	collateralPrice, err := findPrice(prices, quoteArgs.CollateralPoolId)
+	if err != nil {
+		return nil, err
+	}
+
+	// liquidator repay value V, at most close factor of the debt, and receive collateral
+	// worth V / (1 - discount)
+	keep := big.NewInt(int64(10000 - discount))
+	coverable := new(big.Int).Mul(collateral.CollateralValue, keep)
+	coverable.Quo(coverable, big.NewInt(10000))
+	closable := new(big.Int).Mul(debt.DebtValue, big.NewInt(int64(quoteArgs.CloseFactor)))
+	closable.Quo(closable, big.NewInt(10000))
+	quote.MaxRepayValue = minBigInt(closable, coverable)
+	quote.CollateralSeizedValue = new(big.Int).Mul(quote.MaxRepayValue, big.NewInt(10000))
+	quote.CollateralSeizedValue.Quo(quote.CollateralSeizedValue, keep)
+
+	quote.MaxRepayAmount = valueToAmount(quote.MaxRepayValue, debtPrice)
+	quote.CollateralSeizedAmount = valueToAmount(quote.CollateralSeizedValue, collateralPrice)
+	return quote, nil
+}
+
+func findPrice(prices []DolaTokenPrice, dolaPoolId uint16) (DolaTokenPrice, error) {
+	for _, price := range prices {
+		if price.DolaPoolId == dolaPoolId {
+			if price.Price == nil || price.Price.Sign() == 0 {
+				return price, fmt.Errorf("invalid price of pool %d", dolaPoolId)
+			}
+			return price, nil
+		}
+	}
+	return DolaTokenPrice{}, fmt.Errorf("price of pool %d not found", dolaPoolId)
+}
+
+// valueToAmount convert a 1e8 usd value to 1e8 token amount, value = amount * price / 10^decimal
+func valueToAmount(value *big.Int, price DolaTokenPrice) *big.Int {
+	amount := new(big.Int).Mul(value, pow10(price.Decimal))
+	return amount.Quo(amount, price.Price)
+}
+
+// amountToValue convert a 1e8 token amount to 1e8 usd value, value = amount * price / 10^decimal
+func amountToValue(amount *big.Int, price DolaTokenPrice) *big.Int {
+	value := new(big.Int).Mul(amount, price.Price)
+	return value.Quo(value, pow10(price.Decimal))
+}
+
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
+
+func minBigInt(a, b *big.Int) *big.Int {
+	if a.Cmp(b) < 0 {
+		return new(big.Int).Set(a)
+	}
+	return new(big.Int).Set(b)
+}
@@ -0,0 +1,56 @@
+package gosuilending
+
+import (
+	"math/big"
+	"testing"
+)
+
+func Test_quoteLiquidation(t *testing.T) {
+	prices := []DolaTokenPrice{
+		{DolaPoolId: 0, Decimal: 2, Price: big.NewInt(3000000)}, // 30000
+		{DolaPoolId: 1, Decimal: 2, Price: big.NewInt(100)},     // 1
+	}
+	lendingInfo := &UserLendingInfo{
+		HealthFactor: new(big.Int).Div(rayUnit, big.NewInt(2)),
+		CollateralInfos: []CollateralItem{
+			{DolaPoolId: 0, CollateralAmount: big.NewInt(1e7), CollateralValue: big.NewInt(3000e8)},
+		},
+		DebtInfos: []DebtItem{
+			{DolaPoolId: 1, DebtAmount: big.NewInt(5000e8), DebtValue: big.NewInt(5000e8)},
+		},
+	}
+	args := LiquidationQuoteArgs{ViolatorId: "1", DebtPoolId: 1, CollateralPoolId: 0, Discount: 500, CloseFactor: 10000}
+	if _, err := quoteLiquidation(LiquidationQuoteArgs{ViolatorId: "1", DebtPoolId: 1, CloseFactor: 10000}, lendingInfo, prices); err == nil {
+		t.Errorf("quoteLiquidation() should fail without discount")
+	}
+	quote, err := quoteLiquidation(args, lendingInfo, prices)
+	AssertNil(err)
+	if !quote.Liquidatable {
+		t.Fatal("expect liquidatable")
+	}
+	// only 95% of the collateral value can be repaid
+	if quote.MaxRepayValue.Cmp(big.NewInt(2850e8)) != 0 || quote.MaxRepayAmount.Cmp(big.NewInt(2850e8)) != 0 {
+		t.Errorf("max repay = %v/%v", quote.MaxRepayValue, quote.MaxRepayAmount)
+	}
+	if quote.CollateralSeizedValue.Cmp(big.NewInt(3000e8)) != 0 || quote.CollateralSeizedAmount.Cmp(big.NewInt(1e7)) != 0 {
+		t.Errorf("seized = %v/%v", quote.CollateralSeizedValue, quote.CollateralSeizedAmount)
+	}
+
+	// half of the debt at most
+	args.CloseFactor = 5000
+	quote, err = quoteLiquidation(args, lendingInfo, prices)
+	AssertNil(err)
+	if quote.MaxRepayValue.Cmp(big.NewInt(2500e8)) != 0 {
+		t.Errorf("max repay with close factor = %v", quote.MaxRepayValue)
+	}
+	if _, err = quoteLiquidation(LiquidationQuoteArgs{ViolatorId: "1", DebtPoolId: 1, Discount: 500}, lendingInfo, prices); err == nil {
+		t.Errorf("quoteLiquidation() should fail without close factor")
+	}
+
+	lendingInfo.HealthFactor = new(big.Int).Mul(rayUnit, big.NewInt(2))
+	quote, err = quoteLiquidation(args, lendingInfo, prices)
+	AssertNil(err)
+	if quote.Liquidatable || quote.MaxRepayAmount.Sign() != 0 {
+		t.Errorf("healthy user should not be liquidatable")
+	}
+}
@@ -0,0 +1,373 @@
+package gosuilending
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/coming-chat/go-sui/v2/lib"
+	"github.com/coming-chat/go-sui/v2/move_types"
+	"github.com/coming-chat/go-sui/v2/sui_types"
+	"github.com/fardream/go-bcs/bcs"
+)
+
+type (
+	// moveNormalizedFunction is the response of sui_getNormalizedMoveFunction
+	moveNormalizedFunction struct {
+		IsEntry        bool                 `json:"isEntry"`
+		Parameters     []moveNormalizedType `json:"parameters"`
+		Return         []moveNormalizedType `json:"return"`
+		TypeParameters []json.RawMessage    `json:"typeParameters"`
+	}
+
+	moveNormalizedStruct struct {
+		Address       string               `json:"address"`
+		Module        string               `json:"module"`
+		Name          string               `json:"name"`
+		TypeArguments []moveNormalizedType `json:"typeArguments"`
+	}
+
+	// moveNormalizedType is a SuiMoveNormalizedType, either a primitive name like "U64"
+	// or an object with exactly one of the fields below
+	moveNormalizedType struct {
+		Primitive        string
+		Vector           *moveNormalizedType   `json:"Vector,omitempty"`
+		Struct           *moveNormalizedStruct `json:"Struct,omitempty"`
+		Reference        *moveNormalizedType   `json:"Reference,omitempty"`
+		MutableReference *moveNormalizedType   `json:"MutableReference,omitempty"`
+		TypeParameter    *int                  `json:"TypeParameter,omitempty"`
+	}
+)
+
+func (t *moveNormalizedType) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '"' {
+		return json.Unmarshal(data, &t.Primitive)
+	}
+	type plain moveNormalizedType
+	return json.Unmarshal(data, (*plain)(t))
+}
+
+// isTxContext report whether the parameter is the implicit &mut TxContext
+func (t *moveNormalizedType) isTxContext() bool {
+	inner := t.Reference
+	if t.MutableReference != nil {
+		inner = t.MutableReference
+	}
+	if inner == nil || inner.Struct == nil {
+		return false
+	}
+	s := inner.Struct
+	return s.Module == "tx_context" && s.Name == "TxContext" && normalizeHexAddress(s.Address) == normalizeHexAddress("0x2")
+}
+
+// objectStruct return the struct of an object parameter, references removed
+func (t *moveNormalizedType) objectStruct() *moveNormalizedStruct {
+	switch {
+	case t.Reference != nil:
+		return t.Reference.objectStruct()
+	case t.MutableReference != nil:
+		return t.MutableReference.objectStruct()
+	}
+	return t.Struct
+}
+
+// isObject report whether the parameter must be passed as an object argument
+func (t *moveNormalizedType) isObject() bool {
+	switch {
+	case t.Reference != nil:
+		return t.Reference.isObject()
+	case t.MutableReference != nil:
+		return t.MutableReference.isObject()
+	case t.Struct != nil:
+		return !t.isPureStruct()
+	case t.TypeParameter != nil:
+		return true
+	}
+	return false
+}
+
+// isPureStruct report whether the struct is one of the std structs sui accept as pure value
+func (t *moveNormalizedType) isPureStruct() bool {
+	if t.Struct == nil {
+		return false
+	}
+	addr := normalizeHexAddress(t.Struct.Address)
+	switch {
+	case addr == normalizeHexAddress("0x1") && t.Struct.Module == "string" && t.Struct.Name == "String",
+		addr == normalizeHexAddress("0x1") && t.Struct.Module == "ascii" && t.Struct.Name == "String",
+		addr == normalizeHexAddress("0x2") && t.Struct.Module == "object" && t.Struct.Name == "ID":
+		return true
+	}
+	return false
+}
+
+// typeTag convert the normalized type to a TypeTag, type parameters are replaced by typeArgs
+func (t *moveNormalizedType) typeTag(typeArgs []move_types.TypeTag) (move_types.TypeTag, error) {
+	switch {
+	case t.Primitive != "":
+		return parseTypeTag(strings.ToLower(t.Primitive))
+	case t.Vector != nil:
+		inner, err := t.Vector.typeTag(typeArgs)
+		if err != nil {
+			return move_types.TypeTag{}, err
+		}
+		return move_types.TypeTag{Vector: &inner}, nil
+	case t.Struct != nil:
+		address, err := sui_types.NewAddressFromHex(t.Struct.Address)
+		if err != nil {
+			return move_types.TypeTag{}, err
+		}
+		params := make([]move_types.TypeTag, len(t.Struct.TypeArguments))
+		for i := range t.Struct.TypeArguments {
+			if params[i], err = t.Struct.TypeArguments[i].typeTag(typeArgs); err != nil {
+				return move_types.TypeTag{}, err
+			}
+		}
+		return move_types.TypeTag{Struct: &move_types.StructTag{
+			Address:    *address,
+			Module:     move_types.Identifier(t.Struct.Module),
+			Name:       move_types.Identifier(t.Struct.Name),
+			TypeParams: params,
+		}}, nil
+	case t.TypeParameter != nil:
+		if *t.TypeParameter >= len(typeArgs) {
+			return move_types.TypeTag{}, fmt.Errorf("missing type argument %d", *t.TypeParameter)
+		}
+		return typeArgs[*t.TypeParameter], nil
+	}
+	return move_types.TypeTag{}, errors.New("reference has no type tag")
+}
+
+// parseTypeTag parse a move type like u64, vector<u8> or 0x2::coin::Coin<0x2::sui::SUI>.
+// The 0x prefix of struct address is optional
+func parseTypeTag(str string) (move_types.TypeTag, error) {
+	str = strings.TrimSpace(str)
+	switch str {
+	case "bool":
+		return move_types.TypeTag{Bool: &lib.EmptyEnum{}}, nil
+	case "u8":
+		return move_types.TypeTag{U8: &lib.EmptyEnum{}}, nil
+	case "u16":
+		return move_types.TypeTag{U16: &lib.EmptyEnum{}}, nil
+	case "u32":
+		return move_types.TypeTag{U32: &lib.EmptyEnum{}}, nil
+	case "u64":
+		return move_types.TypeTag{U64: &lib.EmptyEnum{}}, nil
+	case "u128":
+		return move_types.TypeTag{U128: &lib.EmptyEnum{}}, nil
+	case "u256":
+		return move_types.TypeTag{U256: &lib.EmptyEnum{}}, nil
+	case "address":
+		return move_types.TypeTag{Address: &lib.EmptyEnum{}}, nil
+	case "signer":
+		return move_types.TypeTag{Signer: &lib.EmptyEnum{}}, nil
+	}
+
+	if strings.HasPrefix(str, "vector<") && strings.HasSuffix(str, ">") {
+		inner, err := parseTypeTag(str[len("vector<") : len(str)-1])
+		if err != nil {
+			return move_types.TypeTag{}, err
+		}
+		return move_types.TypeTag{Vector: &inner}, nil
+	}
+
+	var params []move_types.TypeTag
+	if i := strings.Index(str, "<"); i >= 0 {
+		if !strings.HasSuffix(str, ">") {
+			return move_types.TypeTag{}, fmt.Errorf("invalid type %s", str)
+		}
+		for _, param := range splitTypeParams(str[i+1 : len(str)-1]) {
+			tag, err := parseTypeTag(param)
+			if err != nil {
+				return move_types.TypeTag{}, err
+			}
+			params = append(params, tag)
+		}
+		str = str[:i]
+	}
+	parts := strings.Split(str, "::")
+	if len(parts) != 3 {
+		return move_types.TypeTag{}, fmt.Errorf("invalid type %s", str)
+	}
+	address, err := sui_types.NewAddressFromHex(parts[0])
+	if err != nil {
+		return move_types.TypeTag{}, fmt.Errorf("invalid type %s: %w", str, err)
+	}
+	return move_types.TypeTag{Struct: &move_types.StructTag{
+		Address:    *address,
+		Module:     move_types.Identifier(parts[1]),
+		Name:       move_types.Identifier(parts[2]),
+		TypeParams: params,
+	}}, nil
+}
+
+// splitTypeParams split "A, B<C, D>" to ["A", "B<C, D>"]
+func splitTypeParams(str string) []string {
+	var (
+		result []string
+		depth  int
+		start  int
+	)
+	for i, ch := range str {
+		switch ch {
+		case '<':
+			depth++
+		case '>':
+			depth--
+		case ',':
+			if depth == 0 {
+				result = append(result, str[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(result, str[start:])
+}
+
+// normalizeHexAddress return the full 32 bytes form of a hex address, or the input if it is not an address
+func normalizeHexAddress(str string) string {
+	address, err := sui_types.NewAddressFromHex(str)
+	if err != nil {
+		return str
+	}
+	return address.String()
+}
+
+// encodePureArg bcs encode a go value as the pure move type of param.
+// Accept the same value styles as sui json rpc: numbers may be go integers or decimal strings,
+// vector<u8> may be []byte, a 0x prefixed hex string or an utf8 string
+func encodePureArg(param moveNormalizedType, value any) ([]byte, error) {
+	switch {
+	case param.Primitive != "":
+		return encodePrimitive(param.Primitive, value)
+	case param.isPureStruct():
+		if id, ok := value.(sui_types.ObjectID); ok {
+			return id[:], nil
+		}
+		if param.Struct.Name == "ID" {
+			return encodePrimitive("Address", value)
+		}
+		return encodePureArg(moveNormalizedType{Vector: &moveNormalizedType{Primitive: "U8"}}, value)
+	case param.Vector != nil:
+		if param.Vector.Primitive == "U8" {
+			data, err := toBytes(value)
+			if err != nil {
+				return nil, err
+			}
+			return bcs.Marshal(data)
+		}
+		rv := reflect.ValueOf(value)
+		if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+			return nil, fmt.Errorf("%T is not a vector", value)
+		}
+		buf := bytes.NewBuffer(bcs.ULEB128Encode(rv.Len()))
+		for i := 0; i < rv.Len(); i++ {
+			item, err := encodePureArg(*param.Vector, rv.Index(i).Interface())
+			if err != nil {
+				return nil, fmt.Errorf("vector item %d: %w", i, err)
+			}
+			buf.Write(item)
+		}
+		return buf.Bytes(), nil
+	}
+	return nil, errors.New("unsupported pure argument type")
+}
+
+func encodePrimitive(primitive string, value any) ([]byte, error) {
+	switch primitive {
+	case "Bool":
+		switch v := value.(type) {
+		case bool:
+			return bcs.Marshal(v)
+		case string:
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				return nil, err
+			}
+			return bcs.Marshal(b)
+		}
+		return nil, fmt.Errorf("%T is not a bool", value)
+	case "Address", "Signer":
+		switch v := value.(type) {
+		case sui_types.SuiAddress:
+			return v[:], nil
+		case *sui_types.SuiAddress:
+			return v[:], nil
+		case string:
+			address, err := sui_types.NewAddressFromHex(v)
+			if err != nil {
+				return nil, err
+			}
+			return address[:], nil
+		}
+		return nil, fmt.Errorf("%T is not an address", value)
+	}
+
+	n, err := toBigInt(value)
+	if err != nil {
+		return nil, err
+	}
+	bits := map[string]int{"U8": 8, "U16": 16, "U32": 32, "U64": 64, "U128": 128, "U256": 256}[primitive]
+	if bits == 0 {
+		return nil, fmt.Errorf("unsupported primitive %s", primitive)
+	}
+	if n.Sign() < 0 || n.BitLen() > bits {
+		return nil, fmt.Errorf("%s overflow %s", n.String(), primitive)
+	}
+	return bigIntToLE(n, bits/8), nil
+}
+
+func toBigInt(value any) (*big.Int, error) {
+	switch v := value.(type) {
+	case *big.Int:
+		return v, nil
+	case string:
+		base := 10
+		if strings.HasPrefix(v, "0x") {
+			v, base = v[2:], 16
+		}
+		n, ok := new(big.Int).SetString(v, base)
+		if !ok {
+			return nil, fmt.Errorf("%q is not an integer", v)
+		}
+		return n, nil
+	}
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return big.NewInt(rv.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return new(big.Int).SetUint64(rv.Uint()), nil
+	}
+	return nil, fmt.Errorf("%T is not an integer", value)
+}
+
+func toBytes(value any) ([]byte, error) {
+	switch v := value.(type) {
+	case []byte:
+		return v, nil
+	case string:
+		if strings.HasPrefix(v, "0x") {
+			return hex.DecodeString(strings.TrimPrefix(v, "0x"))
+		}
+		return []byte(v), nil
+	case sui_types.SuiAddress:
+		return v[:], nil
+	}
+	return nil, fmt.Errorf("%T is not vector<u8>", value)
+}
+
+// bigIntToLE encode n as size bytes little endian, the bcs layout of u8..u256
+func bigIntToLE(n *big.Int, size int) []byte {
+	be := n.FillBytes(make([]byte, size))
+	for i, j := 0, len(be)-1; i < j; i, j = i+1, j-1 {
+		be[i], be[j] = be[j], be[i]
+	}
+	return be
+}
@@ -0,0 +1,60 @@
+package gosuilending
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func Test_parseTypeTag(t *testing.T) {
+	tests := []struct {
+		name    string
+		str     string
+		wantErr bool
+	}{
+		{name: "primitive", str: "u64"},
+		{name: "vector", str: "vector<u8>"},
+		{name: "coin without 0x", str: devUSDTAddress},
+		{name: "nested", str: "0x2::coin::Coin<0x2::sui::SUI>"},
+		{name: "multiple params", str: "0x2::dynamic_field::Field<u64, vector<0x2::sui::SUI>>"},
+		{name: "invalid", str: "0x2::coin", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := parseTypeTag(tt.str); (err != nil) != tt.wantErr {
+				t.Errorf("parseTypeTag() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_encodePureArg(t *testing.T) {
+	tests := []struct {
+		name    string
+		param   string
+		value   any
+		want    []byte
+		wantErr bool
+	}{
+		{name: "u64 string", param: `"U64"`, value: "258", want: []byte{2, 1, 0, 0, 0, 0, 0, 0}},
+		{name: "u16", param: `"U16"`, value: uint16(1), want: []byte{1, 0}},
+		{name: "u16 overflow", param: `"U16"`, value: "65536", wantErr: true},
+		{name: "hex bytes", param: `{"Vector":"U8"}`, value: "0x0102", want: []byte{2, 1, 2}},
+		{name: "utf8 bytes", param: `{"Vector":"U8"}`, value: "ab", want: []byte{2, 'a', 'b'}},
+		{name: "u16 vector", param: `{"Vector":"U16"}`, value: []uint16{1, 2}, want: []byte{2, 1, 0, 2, 0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var param moveNormalizedType
+			AssertNil(json.Unmarshal([]byte(tt.param), &param))
+			got, err := encodePureArg(param, tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("encodePureArg() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !bytes.Equal(got, tt.want) {
+				t.Errorf("encodePureArg() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
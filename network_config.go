@@ -0,0 +1,188 @@
+package gosuilending
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/BurntSushi/toml"
+	"github.com/coming-chat/go-sui/v2/client"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	NetworkMainnet = "mainnet"
+
+	// DefaultEnvPrefix is the prefix of the env overrides, e.g. SUI_LENDING_PRICE_ORACLE for PriceOracle
+	DefaultEnvPrefix = "SUI_LENDING_"
+
+	suiClockObjectId = "0x6"
+)
+
+var (
+	// MainnetConfig is the known part of the mainnet deployment. The ids of CoreState,
+	// LendingPortal, LendingCore and PoolApproval are not known yet, they must be set by env or
+	// config file, or found by DiscoverContractConfig
+	MainnetConfig = ContractConfig{
+		LendingPortalPackageId:     "0xc5b2a5049cd71586362d0c6a38e34cfaae7ea9ce6d5401a350506a15f817bf72",
+		ExternalInterfacePackageId: "0x93b49ef245f169342cb07e70b6a4835d4071594451a9df738acbb5ecdcac2e88",
+		BridgePoolPackageId:        "0x5306f64e312b581766351c07af79c72fcb1cd25147157fdc2f8ad76de9a3fb6a",
+		PoolManagerInfo:            "0x1be839a23e544e8d4ba7fab09eab50626c5cfed80f6a22faf7ff71b814689cfb",
+		PoolState:                  "0x5c9d9db2dd5f34154ee59686334f3504026809fa67afe5332837191ee6220586",
+		PriceOracle:                "0x42afbffd3479b06f40c5576799b02ea300df36cf967adcd1ae15445270f572e2",
+		Storage:                    "0xe5a189b1858b207f2cf8c05a09d75bae4271c7a9a8f84a8c199c6896dc7c37e6",
+		WormholeState:              "0xaeab97f96cf9877fee2883315d459552b2b921edc16d7ceac6eab944dd88919c",
+		UserManagerInfo:            "0xee633dc3fd1218d3bd9703fb9b98e6c8d7fdd8c8bf1ca2645ee40d65fb533a3e",
+		Clock:                      suiClockObjectId,
+	}
+
+	networkPresets = map[string]ContractConfig{
+		NetworkMainnet: MainnetConfig,
+	}
+)
+
+// NetworkConfig return the preset of network, only mainnet has one, there is no testnet preset
+func NetworkConfig(network string) (ContractConfig, error) {
+	config, ok := networkPresets[strings.ToLower(network)]
+	if !ok {
+		return ContractConfig{}, fmt.Errorf("unknown network %q", network)
+	}
+	return config, nil
+}
+
+// NewContractFromNetwork create the contract from the preset of network with the env overrides
+// of DefaultEnvPrefix. The fields missing from the preset must be set by env, nothing is fetched
+func NewContractFromNetwork(client *client.Client, network string) (*Contract, error) {
+	config, err := NetworkConfig(network)
+	if err != nil {
+		return nil, err
+	}
+	config = config.WithEnv(DefaultEnvPrefix)
+	if missing := config.Missing(); len(missing) > 0 {
+		return nil, fmt.Errorf("network %s: preset miss %s, set them by env %s<FIELD> or use DiscoverContractConfig",
+			network, strings.Join(missing, ", "), DefaultEnvPrefix)
+	}
+	return NewContract(client, config)
+}
+
+// LoadContractConfig load the config from a .json, .yaml, .yml or .toml file, then apply the env
+// overrides of DefaultEnvPrefix. Keys are the field names or snake case, e.g. PriceOracle or
+// price_oracle, see ParseContractConfig. A "network" key start from its preset
+func LoadContractConfig(path string) (ContractConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ContractConfig{}, err
+	}
+	config, err := ParseContractConfig(data, strings.TrimPrefix(filepath.Ext(path), "."))
+	if err != nil {
+		return ContractConfig{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return config.WithEnv(DefaultEnvPrefix), nil
+}
+
+// ParseContractConfig parse the config of format json, yaml, yml or toml. The document is a flat
+// mapping of config keys to strings, tables and other values are rejected. Quote the ids in yaml,
+// an unquoted 0x1 is a number
+func ParseContractConfig(data []byte, format string) (ContractConfig, error) {
+	var (
+		document map[string]any
+		err      error
+	)
+	switch strings.ToLower(format) {
+	case "json":
+		err = json.Unmarshal(data, &document)
+	case "yaml", "yml":
+		err = yaml.Unmarshal(data, &document)
+	case "toml":
+		err = toml.Unmarshal(data, &document)
+	default:
+		return ContractConfig{}, fmt.Errorf("unsupported config format %q", format)
+	}
+	if err != nil {
+		return ContractConfig{}, err
+	}
+	values := make(map[string]string, len(document))
+	for key, value := range document {
+		str, ok := value.(string)
+		if !ok {
+			return ContractConfig{}, fmt.Errorf("config key %q: expect a string, got %T", key, value)
+		}
+		values[key] = str
+	}
+
+	var config ContractConfig
+	if network, ok := values["network"]; ok {
+		if config, err = NetworkConfig(network); err != nil {
+			return ContractConfig{}, err
+		}
+		delete(values, "network")
+	}
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if err = config.set(key, values[key]); err != nil {
+			return ContractConfig{}, err
+		}
+	}
+	return config, nil
+}
+
+// WithEnv return the config with the fields overridden by the env prefix + snake case field,
+// e.g. SUI_LENDING_LENDING_PORTAL_PACKAGE_ID
+func (c ContractConfig) WithEnv(prefix string) ContractConfig {
+	v := reflect.ValueOf(&c).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		name := prefix + strings.ToUpper(snakeCase(v.Type().Field(i).Name))
+		if value, ok := os.LookupEnv(name); ok && value != "" {
+			v.Field(i).SetString(value)
+		}
+	}
+	return c
+}
+
+// Missing return the empty fields, NewContract need all of them
+func (c ContractConfig) Missing() []string {
+	var missing []string
+	v := reflect.ValueOf(c)
+	for i := 0; i < v.NumField(); i++ {
+		if v.Field(i).String() == "" {
+			missing = append(missing, v.Type().Field(i).Name)
+		}
+	}
+	return missing
+}
+
+func (c *ContractConfig) set(key, value string) error {
+	v := reflect.ValueOf(c).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		if normalizeConfigKey(v.Type().Field(i).Name) == normalizeConfigKey(key) {
+			v.Field(i).SetString(value)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown config key %q", key)
+}
+
+// normalizeConfigKey make PriceOracle, price_oracle and price-oracle the same key
+func normalizeConfigKey(key string) string {
+	return strings.ToLower(strings.NewReplacer("_", "", "-", "").Replace(key))
+}
+
+func snakeCase(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		if unicode.IsUpper(r) && i > 0 {
+			b.WriteByte('_')
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
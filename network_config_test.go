@@ -0,0 +1,78 @@
+package gosuilending
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func Test_LoadContractConfig(t *testing.T) {
+	const oracle = "0x1111111111111111111111111111111111111111111111111111111111111111"
+	files := map[string]string{
+		"config.json": `{"network": "mainnet", "PriceOracle": "` + oracle + `"}`,
+		"config.yaml": "# mainnet with another oracle\nnetwork: mainnet\nprice_oracle: \"" + oracle + "\"\n",
+		"config.toml": "network = \"mainnet\"\nprice-oracle = '" + oracle + "' # comment\n",
+	}
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		AssertNil(os.WriteFile(path, []byte(content), 0o600))
+		config, err := LoadContractConfig(path)
+		AssertNil(err)
+		want := MainnetConfig
+		want.PriceOracle = oracle
+		if !reflect.DeepEqual(config, want) {
+			t.Errorf("%s: LoadContractConfig() = %+v", name, config)
+		}
+	}
+
+	t.Setenv("SUI_LENDING_LENDING_CORE", "0x2")
+	config, err := ParseContractConfig([]byte("network: mainnet\n"), "yaml")
+	AssertNil(err)
+	if config = config.WithEnv(DefaultEnvPrefix); config.LendingCore != "0x2" {
+		t.Errorf("WithEnv() LendingCore = %q", config.LendingCore)
+	}
+	if missing := config.Missing(); !reflect.DeepEqual(missing, []string{"CoreState", "LendingPortal", "PoolApproval"}) {
+		t.Errorf("Missing() = %v", missing)
+	}
+	if _, err = NewContractFromNetwork(nil, NetworkMainnet); err == nil || !strings.Contains(err.Error(), "CoreState, LendingPortal, PoolApproval") {
+		t.Errorf("NewContractFromNetwork() of the incomplete preset = %v", err)
+	}
+	for _, field := range []string{"CORE_STATE", "LENDING_PORTAL", "POOL_APPROVAL"} {
+		t.Setenv(DefaultEnvPrefix+field, "0x3")
+	}
+	contract, err := NewContractFromNetwork(nil, NetworkMainnet)
+	AssertNil(err)
+	if contract.lendingCore.String() != toHex("0x2").String() || contract.poolApproval.String() != toHex("0x3").String() {
+		t.Errorf("NewContractFromNetwork() = %+v", contract)
+	}
+	if _, err = NetworkConfig("testnet"); err == nil {
+		t.Errorf("NetworkConfig() should fail on a network without preset")
+	}
+
+	// real decoders: escapes, multi-line strings, # in values and comments
+	config, err = ParseContractConfig([]byte("price_oracle: \"0x\\u0031\"\nstorage: |-\n  0x2\nclock: a#b # comment\n"), "yaml")
+	AssertNil(err)
+	if config.PriceOracle != "0x1" || config.Storage != "0x2" || config.Clock != "a#b" {
+		t.Errorf("yaml config = %+v", config)
+	}
+	config, err = ParseContractConfig([]byte("price_oracle = \"0x\\u0031\"\nstorage = \"\"\"\n0x2\"\"\"\nclock = 'a#b' # comment\n"), "toml")
+	AssertNil(err)
+	if config.PriceOracle != "0x1" || config.Storage != "0x2" || config.Clock != "a#b" {
+		t.Errorf("toml config = %+v", config)
+	}
+
+	for format, content := range map[string]string{
+		"yaml": "storage:\n  id: 0x1\n",
+		"yml":  "storage: 0x1\n", // a number, not a string
+		"toml": "[mainnet]\nstorage = \"0x1\"\n",
+		"json": `{"unknown": "0x1"}`,
+		"ini":  "storage=0x1",
+	} {
+		if _, err = ParseContractConfig([]byte(content), format); err == nil {
+			t.Errorf("ParseContractConfig(%s) should fail", format)
+		}
+	}
+}
@@ -0,0 +1,374 @@
+package gosuilending
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	OperationSubmitted    OperationState = iota // LendingPortalEvent seen on sui
+	OperationCoreReceived                       // LendingCoreEvent seen, the message reached lending core
+	OperationCoreExecuted                       // LendingCoreExecuteEvent seen, funds are released to the receiver chain
+	OperationFailed                             // core received the message but did not execute it
+	OperationExpired                            // core did not receive the message in time
+)
+
+const (
+	defaultCoreTimeout    = 30 * time.Minute
+	defaultExecuteTimeout = 10 * time.Minute
+)
+
+type (
+	OperationState int
+
+	// OperationKey identify a cross chain operation, nonce is unique on its source chain
+	OperationKey struct {
+		SourceChainId uint16
+		Nonce         uint64
+	}
+
+	// Operation is the lifecycle of one cross chain call, from the sui portal to the lending core
+	Operation struct {
+		OperationKey
+		State      OperationState
+		CallType   int
+		UserId     uint64 // dola user id, known since the core event
+		Sender     string // sui sender of the portal event
+		DstChainId uint16
+		Receiver   []byte
+		Amount     *big.Int
+
+		SubmittedTx string // digest of the portal transaction
+		CoreTx      string // digest of the core transaction
+		Reason      string // why the operation failed or expired
+
+		CreatedAt time.Time
+		UpdatedAt time.Time
+	}
+
+	// TrackerPolicy decide when a pending operation is given up, a zero duration use the default
+	TrackerPolicy struct {
+		CoreTimeout    time.Duration // Submitted longer than this become Expired, 30m by default
+		ExecuteTimeout time.Duration // CoreReceived longer than this become Failed, 10m by default
+	}
+
+	// OperationTracker link portal, core and core execute events into operations
+	OperationTracker struct {
+		lock       sync.RWMutex
+		policy     TrackerPolicy
+		operations map[OperationKey]*Operation
+		// core events of a transaction by event seq, one core transaction may carry several operations
+		byCoreTx map[string][]coreEventRef
+		// execute events not matched to a core event yet, see Unmatched
+		pendingExecutes map[string][]*LendingCoreExecuteEvent
+	}
+
+	coreEventRef struct {
+		seq uint64
+		key OperationKey
+	}
+)
+
+func (s OperationState) String() string {
+	switch s {
+	case OperationSubmitted:
+		return "submitted"
+	case OperationCoreReceived:
+		return "core-received"
+	case OperationCoreExecuted:
+		return "core-executed"
+	case OperationFailed:
+		return "failed"
+	case OperationExpired:
+		return "expired"
+	}
+	return fmt.Sprintf("unknown(%d)", int(s))
+}
+
+// Done report whether the operation will not change any more
+func (s OperationState) Done() bool {
+	return s == OperationCoreExecuted || s == OperationFailed || s == OperationExpired
+}
+
+func NewOperationTracker(policy TrackerPolicy) *OperationTracker {
+	if policy.CoreTimeout == 0 {
+		policy.CoreTimeout = defaultCoreTimeout
+	}
+	if policy.ExecuteTimeout == 0 {
+		policy.ExecuteTimeout = defaultExecuteTimeout
+	}
+	return &OperationTracker{
+		policy:          policy,
+		operations:      make(map[OperationKey]*Operation),
+		byCoreTx:        make(map[string][]coreEventRef),
+		pendingExecutes: make(map[string][]*LendingCoreExecuteEvent),
+	}
+}
+
+// Track update operations by a decoded lending event, LocalLendingEvent is ignored as it never leave sui
+func (t *OperationTracker) Track(event LendingEvent) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	switch e := event.(type) {
+	case *LendingPortalEvent:
+		t.trackPortal(e)
+	case *LendingCoreEvent:
+		t.trackCore(e)
+	case *LendingCoreExecuteEvent:
+		t.trackExecute(e)
+	case *LocalLendingEvent:
+	default:
+		return fmt.Errorf("untracked event %T", event)
+	}
+	return nil
+}
+
+// TrackSubscribed track an event delivered by EventSubscriber
+func (t *OperationTracker) TrackSubscribed(event SubscribedEvent) error {
+	lendingEvent, ok := event.Event.(LendingEvent)
+	if !ok {
+		return fmt.Errorf("untracked event %T", event.Event)
+	}
+	return t.Track(lendingEvent)
+}
+
+func (t *OperationTracker) trackPortal(e *LendingPortalEvent) {
+	op := t.operation(OperationKey{SourceChainId: e.SourceChainId, Nonce: e.Nonce}, e.MoveEventHeader)
+	op.Sender = e.Sender
+	op.CallType = e.CallType
+	op.DstChainId = e.DstChainId
+	op.Receiver = e.Receiver
+	op.Amount = new(big.Int).SetUint64(e.Amount)
+	op.SubmittedTx = e.MoveEventHeader.TxDigest
+}
+
+func (t *OperationTracker) trackCore(e *LendingCoreEvent) {
+	key := OperationKey{SourceChainId: e.SourceChainId, Nonce: e.Nonce}
+	op := t.operation(key, e.MoveEventHeader)
+	op.UserId = e.SenderUserId
+	op.CallType = e.CallType
+	op.DstChainId = e.DstChainId
+	op.Receiver = e.Receiver
+	if op.Amount == nil {
+		op.Amount = new(big.Int).SetUint64(e.Amount)
+	}
+	op.CoreTx = e.MoveEventHeader.TxDigest
+	t.setState(op, OperationCoreReceived, e.MoveEventHeader)
+	t.addCoreEvent(op.CoreTx, coreEventRef{seq: e.MoveEventHeader.Id.EventSeq.Uint64(), key: key})
+
+	pending := t.pendingExecutes[op.CoreTx]
+	delete(t.pendingExecutes, op.CoreTx)
+	for _, execute := range pending {
+		t.trackExecute(execute)
+	}
+}
+
+func (t *OperationTracker) addCoreEvent(digest string, ref coreEventRef) {
+	refs := t.byCoreTx[digest]
+	for _, r := range refs {
+		if r.key == ref.key {
+			return
+		}
+	}
+	refs = append(refs, ref)
+	sort.Slice(refs, func(i, j int) bool { return refs[i].seq < refs[j].seq })
+	t.byCoreTx[digest] = refs
+}
+
+// trackExecute match the execute event to the last core event before it in the same transaction,
+// the core event emitted right before the execution. Events of one transaction may be tracked in
+// any order, so without such core event, or with one of another user, the execute event wait for
+// the next core event of the transaction
+func (t *OperationTracker) trackExecute(e *LendingCoreExecuteEvent) {
+	digest := e.MoveEventHeader.TxDigest
+	seq := e.MoveEventHeader.Id.EventSeq.Uint64()
+	refs := t.byCoreTx[digest]
+	i := sort.Search(len(refs), func(i int) bool { return refs[i].seq > seq }) - 1
+	if i < 0 || t.operations[refs[i].key].UserId != e.UserId {
+		t.pendingExecutes[digest] = append(t.pendingExecutes[digest], e)
+		return
+	}
+	t.setState(t.operations[refs[i].key], OperationCoreExecuted, e.MoveEventHeader)
+}
+
+func (t *OperationTracker) operation(key OperationKey, header MoveEventHeader) *Operation {
+	op, ok := t.operations[key]
+	if !ok {
+		at := eventTime(header)
+		op = &Operation{OperationKey: key, State: OperationSubmitted, CreatedAt: at, UpdatedAt: at}
+		t.operations[key] = op
+	}
+	return op
+}
+
+// setState never move an operation backwards, events may be tracked out of order. A late event
+// revive an expired or failed operation, the event is a fact while the timeout is a guess
+func (t *OperationTracker) setState(op *Operation, state OperationState, header MoveEventHeader) {
+	switch op.State {
+	case OperationCoreExecuted:
+		return
+	case OperationCoreReceived:
+		if state == OperationSubmitted {
+			return
+		}
+	}
+	op.State = state
+	op.Reason = ""
+	op.UpdatedAt = eventTime(header)
+}
+
+// Expire apply the timeout policy at now and return the operations changed
+func (t *OperationTracker) Expire(now time.Time) []Operation {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	var changed []Operation
+	for _, op := range t.operations {
+		switch {
+		case op.State == OperationSubmitted && now.Sub(op.UpdatedAt) > t.policy.CoreTimeout:
+			op.State, op.Reason = OperationExpired, "lending core did not receive the message in time"
+		case op.State == OperationCoreReceived && now.Sub(op.UpdatedAt) > t.policy.ExecuteTimeout:
+			op.State, op.Reason = OperationFailed, "lending core did not execute the message in time"
+		default:
+			continue
+		}
+		op.UpdatedAt = now
+		changed = append(changed, *op)
+	}
+	sortOperations(changed)
+	return changed
+}
+
+// MarkFailed fail an operation by an external reason, e.g. a failed relayer transaction
+func (t *OperationTracker) MarkFailed(key OperationKey, reason string) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	op, ok := t.operations[key]
+	if !ok {
+		return fmt.Errorf("operation %d/%d not found", key.SourceChainId, key.Nonce)
+	}
+	if op.State.Done() {
+		return fmt.Errorf("operation %d/%d is already %s", key.SourceChainId, key.Nonce, op.State)
+	}
+	op.State, op.Reason, op.UpdatedAt = OperationFailed, reason, time.Now()
+	return nil
+}
+
+func (t *OperationTracker) Get(key OperationKey) (Operation, bool) {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+	op, ok := t.operations[key]
+	if !ok {
+		return Operation{}, false
+	}
+	return *op, true
+}
+
+// ByUser return operations of a dola user id, by creation time
+func (t *OperationTracker) ByUser(userId uint64) []Operation {
+	return t.filter(func(op *Operation) bool { return op.UserId == userId })
+}
+
+// BySender return operations submitted by a sui address, by creation time
+func (t *OperationTracker) BySender(sender string) []Operation {
+	return t.filter(func(op *Operation) bool { return op.Sender == sender })
+}
+
+// ByState return operations in state, by creation time
+func (t *OperationTracker) ByState(state OperationState) []Operation {
+	return t.filter(func(op *Operation) bool { return op.State == state })
+}
+
+// Prune drop finished operations last updated before the given time, and execute events of
+// local operations which never have a core event
+func (t *OperationTracker) Prune(before time.Time) int {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	pruned := 0
+	for key, op := range t.operations {
+		if op.State.Done() && op.UpdatedAt.Before(before) {
+			delete(t.operations, key)
+			t.removeCoreEvent(op.CoreTx, key)
+			pruned++
+		}
+	}
+	for digest, executes := range t.pendingExecutes {
+		if time.UnixMilli(int64(executes[0].MoveEventHeader.Timestamp)).Before(before) {
+			delete(t.pendingExecutes, digest)
+		}
+	}
+	return pruned
+}
+
+// Unmatched return execute events not matched to any operation, by time. They are kept until Prune
+func (t *OperationTracker) Unmatched() []LendingCoreExecuteEvent {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+	var result []LendingCoreExecuteEvent
+	for _, executes := range t.pendingExecutes {
+		for _, execute := range executes {
+			result = append(result, *execute)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		a, b := result[i].MoveEventHeader, result[j].MoveEventHeader
+		if a.Timestamp != b.Timestamp {
+			return a.Timestamp < b.Timestamp
+		}
+		if a.TxDigest != b.TxDigest {
+			return a.TxDigest < b.TxDigest
+		}
+		return a.Id.EventSeq.Uint64() < b.Id.EventSeq.Uint64()
+	})
+	return result
+}
+
+func (t *OperationTracker) removeCoreEvent(digest string, key OperationKey) {
+	refs := t.byCoreTx[digest]
+	for i, ref := range refs {
+		if ref.key == key {
+			refs = append(refs[:i], refs[i+1:]...)
+			break
+		}
+	}
+	if len(refs) == 0 {
+		delete(t.byCoreTx, digest)
+		return
+	}
+	t.byCoreTx[digest] = refs
+}
+
+func (t *OperationTracker) filter(match func(op *Operation) bool) []Operation {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+	var result []Operation
+	for _, op := range t.operations {
+		if match(op) {
+			result = append(result, *op)
+		}
+	}
+	sortOperations(result)
+	return result
+}
+
+func sortOperations(ops []Operation) {
+	sort.Slice(ops, func(i, j int) bool {
+		if !ops[i].CreatedAt.Equal(ops[j].CreatedAt) {
+			return ops[i].CreatedAt.Before(ops[j].CreatedAt)
+		}
+		if ops[i].SourceChainId != ops[j].SourceChainId {
+			return ops[i].SourceChainId < ops[j].SourceChainId
+		}
+		return ops[i].Nonce < ops[j].Nonce
+	})
+}
+
+// eventTime return the checkpoint time of the event, or now for events without timestamp
+func eventTime(header MoveEventHeader) time.Time {
+	if header.Timestamp == 0 {
+		return time.Now()
+	}
+	return time.UnixMilli(int64(header.Timestamp))
+}
@@ -0,0 +1,92 @@
+package gosuilending
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/coming-chat/go-sui/v2/types"
+)
+
+func Test_OperationTracker(t *testing.T) {
+	tracker := NewOperationTracker(TrackerPolicy{CoreTimeout: time.Minute, ExecuteTimeout: time.Minute})
+	start := time.UnixMilli(1_690_000_000_000)
+	header := func(digest string, at time.Time) MoveEventHeader {
+		return MoveEventHeader{EventHeader: EventHeader{Timestamp: uint64(at.UnixMilli()), TxDigest: digest}}
+	}
+
+	AssertNil(tracker.Track(&LendingPortalEvent{MoveEventHeader: header("portal1", start), Nonce: 1, SourceChainId: 0, Sender: "0xa", Amount: 100, CallType: CallTypeWithdraw}))
+	AssertNil(tracker.Track(&LendingPortalEvent{MoveEventHeader: header("portal2", start), Nonce: 2, SourceChainId: 0, Sender: "0xa", Amount: 200, CallType: CallTypeBorrow}))
+
+	// execute event tracked before the core event of the same transaction
+	AssertNil(tracker.Track(&LendingCoreExecuteEvent{MoveEventHeader: header("core1", start.Add(time.Second)), UserId: 7, Amount: big.NewInt(100), CallType: CallTypeWithdraw}))
+	AssertNil(tracker.Track(&LendingCoreEvent{MoveEventHeader: header("core1", start.Add(time.Second)), Nonce: 1, SourceChainId: 0, SenderUserId: 7, Amount: 100, CallType: CallTypeWithdraw}))
+
+	op, ok := tracker.Get(OperationKey{SourceChainId: 0, Nonce: 1})
+	if !ok || op.State != OperationCoreExecuted || op.UserId != 7 || op.SubmittedTx != "portal1" || op.CoreTx != "core1" {
+		t.Errorf("Get() = %+v, %v", op, ok)
+	}
+
+	changed := tracker.Expire(start.Add(2 * time.Minute))
+	if len(changed) != 1 || changed[0].Nonce != 2 || changed[0].State != OperationExpired {
+		t.Errorf("Expire() = %+v", changed)
+	}
+
+	// a late core event revive the expired operation
+	AssertNil(tracker.Track(&LendingCoreEvent{MoveEventHeader: header("core2", start.Add(3*time.Minute)), Nonce: 2, SourceChainId: 0, SenderUserId: 7, Amount: 200, CallType: CallTypeBorrow}))
+	if ops := tracker.ByState(OperationCoreReceived); len(ops) != 1 || ops[0].Nonce != 2 {
+		t.Errorf("ByState() = %+v", ops)
+	}
+	changed = tracker.Expire(start.Add(5 * time.Minute))
+	if len(changed) != 1 || changed[0].State != OperationFailed {
+		t.Errorf("Expire() = %+v", changed)
+	}
+
+	if ops := tracker.ByUser(7); len(ops) != 2 || ops[0].Nonce != 1 || ops[1].Nonce != 2 {
+		t.Errorf("ByUser() = %+v", ops)
+	}
+	if err := tracker.MarkFailed(OperationKey{SourceChainId: 0, Nonce: 1}, "test"); err == nil {
+		t.Errorf("MarkFailed() should fail on an executed operation")
+	}
+	if pruned := tracker.Prune(start.Add(time.Hour)); pruned != 2 {
+		t.Errorf("Prune() = %d", pruned)
+	}
+}
+
+func Test_OperationTracker_sameCoreTx(t *testing.T) {
+	tracker := NewOperationTracker(TrackerPolicy{})
+	header := func(digest string, seq uint64) MoveEventHeader {
+		return MoveEventHeader{EventHeader: EventHeader{Timestamp: 1_690_000_000_000 + seq, TxDigest: digest, Id: types.EventId{EventSeq: types.NewSafeSuiBigInt(seq)}}}
+	}
+
+	// one core transaction execute two operations, core event then its execute event
+	AssertNil(tracker.Track(&LendingCoreExecuteEvent{MoveEventHeader: header("core", 3), UserId: 8, Amount: big.NewInt(200), CallType: CallTypeBorrow}))
+	AssertNil(tracker.Track(&LendingCoreExecuteEvent{MoveEventHeader: header("core", 1), UserId: 7, Amount: big.NewInt(100), CallType: CallTypeWithdraw}))
+	if unmatched := tracker.Unmatched(); len(unmatched) != 2 || unmatched[0].UserId != 7 {
+		t.Errorf("Unmatched() = %+v", unmatched)
+	}
+	AssertNil(tracker.Track(&LendingCoreEvent{MoveEventHeader: header("core", 0), Nonce: 1, SourceChainId: 0, SenderUserId: 7, Amount: 100, CallType: CallTypeWithdraw}))
+	// the execute event of user 8 wait for the core event after the first one
+	if unmatched := tracker.Unmatched(); len(unmatched) != 1 || unmatched[0].UserId != 8 {
+		t.Errorf("Unmatched() = %+v", unmatched)
+	}
+	AssertNil(tracker.Track(&LendingCoreEvent{MoveEventHeader: header("core", 2), Nonce: 2, SourceChainId: 0, SenderUserId: 8, Amount: 200, CallType: CallTypeBorrow}))
+	for _, nonce := range []uint64{1, 2} {
+		if op, ok := tracker.Get(OperationKey{Nonce: nonce}); !ok || op.State != OperationCoreExecuted || op.CoreTx != "core" {
+			t.Errorf("Get(%d) = %+v, %v", nonce, op, ok)
+		}
+	}
+	if unmatched := tracker.Unmatched(); len(unmatched) != 0 {
+		t.Errorf("Unmatched() = %+v", unmatched)
+	}
+
+	// an execute event of another user is not matched, but reported
+	AssertNil(tracker.Track(&LendingCoreEvent{MoveEventHeader: header("core2", 0), Nonce: 3, SourceChainId: 0, SenderUserId: 7, Amount: 100, CallType: CallTypeWithdraw}))
+	AssertNil(tracker.Track(&LendingCoreExecuteEvent{MoveEventHeader: header("core2", 1), UserId: 9, Amount: big.NewInt(100), CallType: CallTypeWithdraw}))
+	if op, _ := tracker.Get(OperationKey{Nonce: 3}); op.State != OperationCoreReceived {
+		t.Errorf("Get(3) = %+v", op)
+	}
+	if unmatched := tracker.Unmatched(); len(unmatched) != 1 || unmatched[0].UserId != 9 {
+		t.Errorf("Unmatched() = %+v", unmatched)
+	}
+}
@@ -0,0 +1,331 @@
+package gosuilending
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/coming-chat/go-sui/v2/client"
+	"github.com/coming-chat/go-sui/v2/move_types"
+	"github.com/coming-chat/go-sui/v2/sui_types"
+	"github.com/coming-chat/go-sui/v2/types"
+)
+
+const upgradeCapType = "0x2::package::UpgradeCap"
+
+type (
+	// PackageInfo is the upgrade chain of a package. Calls must target Latest, while types and
+	// events keep the package which defined them first, Original for the types of the first version
+	PackageInfo struct {
+		Configured  sui_types.ObjectID
+		Original    sui_types.ObjectID
+		Latest      sui_types.ObjectID
+		Version     uint64                        // version of Latest, 1 if never upgraded
+		UpgradeCap  *sui_types.ObjectID           // nil if the cap is not found, the package is then immutable
+		TypeOrigins map[string]sui_types.ObjectID // module::Struct -> defining package
+	}
+
+	// StalePackageError report the configured package ids which are not the latest version
+	StalePackageError struct {
+		Packages []PackageInfo
+	}
+)
+
+// Stale is true if Configured is an old version
+func (p PackageInfo) Stale() bool {
+	return p.Configured != p.Latest
+}
+
+// TypeOrigin return the package defining module::name, Original if unknown
+func (p PackageInfo) TypeOrigin(module, name string) sui_types.ObjectID {
+	if origin, ok := p.TypeOrigins[module+"::"+name]; ok {
+		return origin
+	}
+	return p.Original
+}
+
+// contains is true if id is a version of the package
+func (p PackageInfo) contains(id sui_types.ObjectID) bool {
+	if id == p.Configured || id == p.Original || id == p.Latest {
+		return true
+	}
+	for _, origin := range p.TypeOrigins {
+		if origin == id {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *StalePackageError) Error() string {
+	stale := make([]string, len(e.Packages))
+	for i, info := range e.Packages {
+		stale[i] = fmt.Sprintf("%s upgraded to %s (version %d)", info.Configured, info.Latest, info.Version)
+	}
+	return fmt.Sprintf("stale package: %s", strings.Join(stale, ", "))
+}
+
+// ResolvePackages resolve the upgrade chain of the three configured packages and of the lending
+// core package, see LendingCorePackageId. Calls target the latest versions after it, type
+// arguments of these packages are rewritten to their defining package, and Contract.LendingEventTypes,
+// NewLendingEventRegistry, NewLendingEventSubscriber and NewExecutor use the package defining each
+// event. A *StalePackageError is returned if a configured id is not the latest, the contract is
+// usable anyway
+func (c *Contract) ResolvePackages(ctx context.Context) ([]PackageInfo, error) {
+	var (
+		infos []PackageInfo
+		stale []PackageInfo
+	)
+	lendingCore, err := c.LendingCorePackageId(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("lending core package: %w", err)
+	}
+	for _, pkg := range []*sui_types.ObjectID{c.lendingPortalPackageId, c.externalInterfacePackageId, c.bridgePoolPackageId, &lendingCore} {
+		info, err := ResolvePackage(ctx, c.client, *pkg)
+		if err != nil {
+			return nil, fmt.Errorf("resolve package %s: %w", pkg, err)
+		}
+		infos = append(infos, *info)
+		if info.Stale() {
+			stale = append(stale, *info)
+		}
+	}
+
+	c.cacheLock.Lock()
+	if c.packages == nil {
+		c.packages = make(map[sui_types.ObjectID]*PackageInfo)
+	}
+	for i := range infos {
+		c.packages[infos[i].Configured] = &infos[i]
+	}
+	c.cacheLock.Unlock()
+
+	if len(stale) > 0 {
+		return infos, &StalePackageError{Packages: stale}
+	}
+	return infos, nil
+}
+
+// PackageInfo return the resolved upgrade chain of a configured package id, see ResolvePackages
+func (c *Contract) PackageInfo(configured sui_types.ObjectID) (PackageInfo, bool) {
+	c.cacheLock.Lock()
+	defer c.cacheLock.Unlock()
+	info, ok := c.packages[configured]
+	if !ok {
+		return PackageInfo{}, false
+	}
+	return *info, true
+}
+
+// ResolvePackage find the upgrade cap of pkg in its publish or upgrade transaction, the cap
+// keep the latest version of the package
+func ResolvePackage(ctx context.Context, client *client.Client, pkg sui_types.ObjectID) (*PackageInfo, error) {
+	object, err := client.GetObject(ctx, pkg, &types.SuiObjectDataOptions{ShowType: true, ShowBcs: true, ShowPreviousTransaction: true})
+	if err != nil {
+		return nil, err
+	}
+	if object.Data == nil || object.Data.Type == nil || *object.Data.Type != "package" {
+		return nil, errors.New("not a package")
+	}
+	info := &PackageInfo{Configured: pkg, Original: pkg, Latest: pkg, Version: object.Data.Version.Uint64()}
+
+	latest := object.Data
+	if object.Data.PreviousTransaction != nil {
+		if info.UpgradeCap, err = findUpgradeCap(ctx, client, *object.Data.PreviousTransaction); err != nil {
+			return nil, err
+		}
+	}
+	if info.UpgradeCap != nil {
+		upgradeCap, err := client.GetObject(ctx, *info.UpgradeCap, &types.SuiObjectDataOptions{ShowContent: true})
+		if err != nil {
+			return nil, err
+		}
+		if upgradeCap.Data == nil || upgradeCap.Data.Content == nil || upgradeCap.Data.Content.Data.MoveObject == nil {
+			return nil, fmt.Errorf("upgrade cap %s not found", info.UpgradeCap)
+		}
+		if info.Latest, info.Version, err = parseUpgradeCap(upgradeCap.Data.Content.Data.MoveObject.Fields); err != nil {
+			return nil, fmt.Errorf("upgrade cap %s: %w", info.UpgradeCap, err)
+		}
+		if info.Latest != pkg {
+			object, err = client.GetObject(ctx, info.Latest, &types.SuiObjectDataOptions{ShowBcs: true})
+			if err != nil {
+				return nil, err
+			}
+			if object.Data == nil {
+				return nil, fmt.Errorf("latest package %s not found", info.Latest)
+			}
+			latest = object.Data
+		}
+	}
+
+	if latest.Bcs == nil || latest.Bcs.Data.Package == nil {
+		return nil, fmt.Errorf("package %s has no bcs", info.Latest)
+	}
+	info.TypeOrigins = make(map[string]sui_types.ObjectID)
+	for _, origin := range latest.Bcs.Data.Package.TypeOriginTable {
+		info.TypeOrigins[origin.ModuleName+"::"+origin.StructName] = origin.Package
+	}
+	if info.Original, err = originalPackage(ctx, client, info); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// findUpgradeCap return the upgrade cap created or mutated by a publish or upgrade transaction
+func findUpgradeCap(ctx context.Context, client *client.Client, digest sui_types.TransactionDigest) (*sui_types.ObjectID, error) {
+	tx, err := client.GetTransactionBlock(ctx, digest, types.SuiTransactionBlockResponseOptions{ShowObjectChanges: true})
+	if err != nil {
+		return nil, err
+	}
+	var caps []sui_types.ObjectID
+	for _, change := range tx.ObjectChanges {
+		switch {
+		case change.Data.Created != nil && isUpgradeCap(change.Data.Created.ObjectType):
+			caps = append(caps, change.Data.Created.ObjectId)
+		case change.Data.Mutated != nil && isUpgradeCap(change.Data.Mutated.ObjectType):
+			caps = append(caps, change.Data.Mutated.ObjectId)
+		}
+	}
+	switch len(caps) {
+	case 0:
+		return nil, nil
+	case 1:
+		return &caps[0], nil
+	default:
+		return nil, fmt.Errorf("transaction %s has %d upgrade caps", digest, len(caps))
+	}
+}
+
+func isUpgradeCap(objectType string) bool {
+	normalized, err := normalizeMoveType(objectType)
+	if err != nil {
+		return false
+	}
+	want, _ := normalizeMoveType(upgradeCapType)
+	return normalized == want
+}
+
+// parseUpgradeCap read the package and version fields of an UpgradeCap
+func parseUpgradeCap(fields any) (sui_types.ObjectID, uint64, error) {
+	values, ok := fields.(map[string]any)
+	if !ok {
+		return sui_types.ObjectID{}, 0, errors.New("invalid fields")
+	}
+	pkg, _ := values["package"].(string)
+	latest, err := sui_types.NewObjectIdFromHex(pkg)
+	if err != nil {
+		return sui_types.ObjectID{}, 0, fmt.Errorf("invalid package %q", pkg)
+	}
+	version, err := strconv.ParseUint(fmt.Sprint(values["version"]), 10, 64)
+	if err != nil {
+		return sui_types.ObjectID{}, 0, fmt.Errorf("invalid version %v", values["version"])
+	}
+	return *latest, version, nil
+}
+
+// originalPackage return the type origin with the lowest version, the first version of the
+// package. A package without types is its own original
+func originalPackage(ctx context.Context, client *client.Client, info *PackageInfo) (sui_types.ObjectID, error) {
+	seen := make(map[sui_types.ObjectID]bool)
+	var origins []sui_types.ObjectID
+	for _, origin := range info.TypeOrigins {
+		if !seen[origin] {
+			seen[origin] = true
+			origins = append(origins, origin)
+		}
+	}
+	if len(origins) == 0 {
+		return info.Configured, nil
+	}
+	if len(origins) == 1 {
+		return origins[0], nil
+	}
+	objects, err := client.MultiGetObjects(ctx, origins, &types.SuiObjectDataOptions{})
+	if err != nil {
+		return sui_types.ObjectID{}, err
+	}
+	versions := make(map[sui_types.ObjectID]uint64, len(objects))
+	for i, object := range objects {
+		if object.Data == nil {
+			return sui_types.ObjectID{}, fmt.Errorf("type origin %s not found", origins[i])
+		}
+		versions[origins[i]] = object.Data.Version.Uint64()
+	}
+	return lowestVersion(origins, versions), nil
+}
+
+func lowestVersion(ids []sui_types.ObjectID, versions map[sui_types.ObjectID]uint64) sui_types.ObjectID {
+	sorted := append([]sui_types.ObjectID{}, ids...)
+	sort.Slice(sorted, func(i, j int) bool { return versions[sorted[i]] < versions[sorted[j]] })
+	return sorted[0]
+}
+
+// resolveCall return call targeting the latest version of its package, with the type arguments
+// of resolved packages using their defining package
+func (c *Contract) resolveCall(call moveCall) moveCall {
+	c.cacheLock.Lock()
+	defer c.cacheLock.Unlock()
+	if len(c.packages) == 0 {
+		return call
+	}
+	if info, ok := c.packages[*call.packageId]; ok {
+		latest := info.Latest
+		call.packageId = &latest
+	}
+	typeArgs := make([]string, len(call.typeArgs))
+	for i, typeArg := range call.typeArgs {
+		typeArgs[i] = typeArg
+		tag, err := parseTypeTag(typeArg)
+		if err != nil {
+			// reported when the call is built
+			continue
+		}
+		if c.originTypeTag(&tag) {
+			typeArgs[i] = formatTypeTag(tag)
+		}
+	}
+	call.typeArgs = typeArgs
+	return call
+}
+
+// originTypeTag replace the addresses of resolved packages in tag by their defining package,
+// return true if tag is changed. cacheLock must be held
+func (c *Contract) originTypeTag(tag *move_types.TypeTag) bool {
+	switch {
+	case tag.Vector != nil:
+		return c.originTypeTag(tag.Vector)
+	case tag.Struct != nil:
+		changed := false
+		for i := range tag.Struct.TypeParams {
+			if c.originTypeTag(&tag.Struct.TypeParams[i]) {
+				changed = true
+			}
+		}
+		for _, info := range c.packages {
+			if info.contains(tag.Struct.Address) {
+				origin := info.TypeOrigin(string(tag.Struct.Module), string(tag.Struct.Name))
+				if origin != tag.Struct.Address {
+					tag.Struct.Address = origin
+					changed = true
+				}
+				break
+			}
+		}
+		return changed
+	}
+	return false
+}
+
+// samePackage is true if id is pkg or a version of pkg
+func (c *Contract) samePackage(pkg, id sui_types.ObjectID) bool {
+	if pkg == id {
+		return true
+	}
+	c.cacheLock.Lock()
+	defer c.cacheLock.Unlock()
+	info, ok := c.packages[pkg]
+	return ok && info.contains(id)
+}
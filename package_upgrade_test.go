@@ -0,0 +1,139 @@
+package gosuilending
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/coming-chat/go-sui/v2/sui_types"
+	"github.com/coming-chat/go-sui/v2/types"
+)
+
+func Test_PackageUpgrade(t *testing.T) {
+	latest, version, err := parseUpgradeCap(map[string]any{
+		"id":      map[string]any{"id": "0x9"},
+		"package": "0xb2",
+		"policy":  float64(0),
+		"version": "3",
+	})
+	AssertNil(err)
+	if latest != *toHex("0xb2") || version != 3 {
+		t.Errorf("parseUpgradeCap() = %s, %d", latest, version)
+	}
+	if _, _, err = parseUpgradeCap(map[string]any{"package": "0xb2"}); err == nil {
+		t.Errorf("parseUpgradeCap() should fail without version")
+	}
+
+	ids := []sui_types.ObjectID{*toHex("0xb1"), *toHex("0xa1")}
+	versions := map[sui_types.ObjectID]uint64{*toHex("0xb1"): 2, *toHex("0xa1"): 1}
+	if original := lowestVersion(ids, versions); original != *toHex("0xa1") {
+		t.Errorf("lowestVersion() = %s", original)
+	}
+
+	// 0xa1 upgraded to 0xb1 adding module pool, then to 0xc1, 0xb1 is configured
+	contract := &Contract{externalInterfacePackageId: toHex("0xb1")}
+	info := &PackageInfo{
+		Configured: *toHex("0xb1"),
+		Original:   *toHex("0xa1"),
+		Latest:     *toHex("0xc1"),
+		Version:    3,
+		TypeOrigins: map[string]sui_types.ObjectID{
+			"lending::LendingPortal": *toHex("0xa1"),
+			"pool::Pool":             *toHex("0xb1"),
+		},
+	}
+	contract.packages = map[sui_types.ObjectID]*PackageInfo{info.Configured: info}
+	if !info.Stale() {
+		t.Errorf("Stale() = false")
+	}
+
+	call := contract.resolveCall(moveCall{
+		packageId: toHex("0xb1"),
+		module:    "lending",
+		function:  "supply",
+		typeArgs:  []string{"0xc1::pool::Pool<0xc1::lending::LendingPortal>", "0x2::sui::SUI"},
+	})
+	if *call.packageId != info.Latest {
+		t.Errorf("resolveCall() package = %s", call.packageId)
+	}
+	want := []string{
+		toHex("0xb1").String() + "::pool::Pool<" + toHex("0xa1").String() + "::lending::LendingPortal>",
+		"0x2::sui::SUI",
+	}
+	for i := range want {
+		normalized, err := normalizeMoveType(call.typeArgs[i])
+		AssertNil(err)
+		expected, err := normalizeMoveType(want[i])
+		AssertNil(err)
+		if normalized != expected {
+			t.Errorf("resolveCall() type arg %d = %s, want %s", i, call.typeArgs[i], want[i])
+		}
+	}
+	if !contract.samePackage(info.Configured, *toHex("0xc1")) || contract.samePackage(info.Configured, *toHex("0xd1")) {
+		t.Errorf("samePackage() mismatch")
+	}
+}
+
+func Test_PackageUpgrade_eventTypes(t *testing.T) {
+	// portal 0xa1 upgraded to 0xa2 adding LendingPortalEvent, core 0xc1 upgraded to 0xc2 adding logic
+	contract := &Contract{lendingPortalPackageId: toHex("0xa2"), lendingCorePackage: toHex("0xc1")}
+	portal := &PackageInfo{
+		Configured: *toHex("0xa2"),
+		Original:   *toHex("0xa1"),
+		Latest:     *toHex("0xa2"),
+		TypeOrigins: map[string]sui_types.ObjectID{
+			"lending::LocalLendingEvent":  *toHex("0xa1"),
+			"lending::LendingPortalEvent": *toHex("0xa2"),
+		},
+	}
+	core := &PackageInfo{
+		Configured: *toHex("0xc1"),
+		Original:   *toHex("0xc1"),
+		Latest:     *toHex("0xc2"),
+		TypeOrigins: map[string]sui_types.ObjectID{
+			"logic::LendingCoreExecuteEvent": *toHex("0xc2"),
+		},
+	}
+	contract.packages = map[sui_types.ObjectID]*PackageInfo{portal.Configured: portal, core.Configured: core}
+
+	eventTypes, err := contract.LendingEventTypes(context.Background())
+	AssertNil(err)
+	want := []string{
+		toHex("0xa1").String() + "::lending::LocalLendingEvent",
+		toHex("0xa2").String() + "::lending::LendingPortalEvent",
+		toHex("0xc1").String() + "::wormhole_adapter::LendingCoreEvent",
+		toHex("0xc2").String() + "::logic::LendingCoreExecuteEvent",
+	}
+	if fmt.Sprint(eventTypes) != fmt.Sprint(want) {
+		t.Errorf("LendingEventTypes() = %v, want %v", eventTypes, want)
+	}
+	if executor := contract.NewExecutor(ExecuteOptions{}); fmt.Sprint(executor.options.LendingEventTypes) != fmt.Sprint(want[:2]) {
+		t.Errorf("NewExecutor() event types = %v", executor.options.LendingEventTypes)
+	}
+
+	registry, err := contract.NewLendingEventRegistry(context.Background())
+	AssertNil(err)
+	event := types.SuiEvent{Type: "0xa1::lending::LocalLendingEvent", ParsedJson: map[string]any{
+		"nonce": "1", "amount": "100", "sender": "0x1", "call_type": float64(CallTypeSupply), "dola_pool_address": []any{float64(1)},
+	}}
+	if _, err = registry.Decode(event); err != nil {
+		t.Errorf("Decode() = %v", err)
+	}
+	event.Type = "0xa2::lending::LocalLendingEvent"
+	if _, err = registry.Decode(event); err == nil {
+		t.Errorf("Decode() of the upgraded package type should fail")
+	}
+}
+
+func TestContract_ResolvePackages(t *testing.T) {
+	contract := getDevContract()
+	infos, err := contract.ResolvePackages(context.Background())
+	var stale *StalePackageError
+	if err != nil && !errors.As(err, &stale) {
+		AssertNil(err)
+	}
+	for _, info := range infos {
+		t.Logf("%s original %s latest %s version %d", info.Configured, info.Original, info.Latest, info.Version)
+	}
+}
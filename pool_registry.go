@@ -0,0 +1,327 @@
+package gosuilending
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/coming-chat/go-sui/v2/sui_types"
+	"github.com/coming-chat/go-sui/v2/types"
+)
+
+type (
+	// PoolToken is a dola pool and its token on every chain
+	PoolToken struct {
+		DolaPoolId    uint16
+		Symbol        string
+		Decimals      int32               // decimals of the sui coin
+		CoinType      string              // sui coin type, the type argument of the lending calls
+		Pool          *sui_types.ObjectID // sui pool object, nil if not loaded
+		PoolAddresses map[uint16]string   // dola chain id -> pool address, native format of the chain
+	}
+
+	// PoolRegistry map dola pool ids, symbols and sui coin types to the pool tokens
+	PoolRegistry struct {
+		lock       sync.RWMutex
+		tokens     map[uint16]*PoolToken
+		bySymbol   map[string][]uint16 // upper case symbol, several pools may share a symbol
+		byCoinType map[string]uint16   // normalized coin type
+	}
+
+	// PoolContract build the lending calls by token symbol and human readable amounts, e.g.
+	// Supply(ctx, signer, "USDT", "1.5"). The call options are DefaultPoolCallOptions unless
+	// passed or set by WithCallOptions
+	PoolContract struct {
+		contract    *Contract
+		registry    *PoolRegistry
+		callOptions CallOptions
+	}
+)
+
+// DefaultPoolCallOptions is the default call options of PoolContract, the gas budget is dry run
+var DefaultPoolCallOptions = CallOptions{AutoGasBudget: true}
+
+func NewPoolRegistry() *PoolRegistry {
+	return &PoolRegistry{
+		tokens:     make(map[uint16]*PoolToken),
+		bySymbol:   make(map[string][]uint16),
+		byCoinType: make(map[string]uint16),
+	}
+}
+
+// Register add or replace the token of token.DolaPoolId
+func (r *PoolRegistry) Register(token PoolToken) error {
+	coinType, err := normalizeMoveType(token.CoinType)
+	if err != nil {
+		return fmt.Errorf("pool %d: invalid coin type: %w", token.DolaPoolId, err)
+	}
+	token.CoinType = coinType
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if id, ok := r.byCoinType[coinType]; ok && id != token.DolaPoolId {
+		return fmt.Errorf("pool %d: coin type %s is registered by pool %d", token.DolaPoolId, coinType, id)
+	}
+	if old, ok := r.tokens[token.DolaPoolId]; ok {
+		r.unindex(old)
+	}
+	r.tokens[token.DolaPoolId] = &token
+	symbol := strings.ToUpper(token.Symbol)
+	r.bySymbol[symbol] = append(r.bySymbol[symbol], token.DolaPoolId)
+	r.byCoinType[coinType] = token.DolaPoolId
+	return nil
+}
+
+func (r *PoolRegistry) unindex(token *PoolToken) {
+	symbol := strings.ToUpper(token.Symbol)
+	ids := r.bySymbol[symbol][:0]
+	for _, id := range r.bySymbol[symbol] {
+		if id != token.DolaPoolId {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		delete(r.bySymbol, symbol)
+	} else {
+		r.bySymbol[symbol] = ids
+	}
+	delete(r.byCoinType, token.CoinType)
+}
+
+// SetPool set the sui pool object of dolaPoolId
+func (r *PoolRegistry) SetPool(dolaPoolId uint16, pool sui_types.ObjectID) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	token, ok := r.tokens[dolaPoolId]
+	if !ok {
+		return fmt.Errorf("pool %d not registered", dolaPoolId)
+	}
+	token.Pool = &pool
+	return nil
+}
+
+func (r *PoolRegistry) Token(dolaPoolId uint16) (PoolToken, error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	token, ok := r.tokens[dolaPoolId]
+	if !ok {
+		return PoolToken{}, fmt.Errorf("pool %d not registered", dolaPoolId)
+	}
+	return *token, nil
+}
+
+// TokenBySymbol find the token by its symbol, case insensitive. A symbol shared by several
+// pools is an error, use Token with the dola pool id instead
+func (r *PoolRegistry) TokenBySymbol(symbol string) (PoolToken, error) {
+	r.lock.RLock()
+	ids := r.bySymbol[strings.ToUpper(symbol)]
+	r.lock.RUnlock()
+	switch len(ids) {
+	case 0:
+		return PoolToken{}, fmt.Errorf("symbol %s not registered", symbol)
+	case 1:
+		return r.Token(ids[0])
+	default:
+		sorted := append([]uint16{}, ids...)
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+		return PoolToken{}, fmt.Errorf("symbol %s is ambiguous, pools %v", symbol, sorted)
+	}
+}
+
+func (r *PoolRegistry) TokenByCoinType(coinType string) (PoolToken, error) {
+	normalized, err := normalizeMoveType(coinType)
+	if err != nil {
+		return PoolToken{}, err
+	}
+	r.lock.RLock()
+	id, ok := r.byCoinType[normalized]
+	r.lock.RUnlock()
+	if !ok {
+		return PoolToken{}, fmt.Errorf("coin type %s not registered", coinType)
+	}
+	return r.Token(id)
+}
+
+// Tokens return all tokens ordered by dola pool id
+func (r *PoolRegistry) Tokens() []PoolToken {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	tokens := make([]PoolToken, 0, len(r.tokens))
+	for _, token := range r.tokens {
+		tokens = append(tokens, *token)
+	}
+	sort.Slice(tokens, func(i, j int) bool { return tokens[i].DolaPoolId < tokens[j].DolaPoolId })
+	return tokens
+}
+
+// PoolDecimals return the decimals of all tokens
+func (r *PoolRegistry) PoolDecimals() *PoolDecimals {
+	decimals := NewPoolDecimals(nil)
+	for _, token := range r.Tokens() {
+		decimals.Set(token.DolaPoolId, token.Decimals)
+	}
+	return decimals
+}
+
+// LoadPoolRegistry register every dola pool of GetAllReserveInfo with a sui pool, its symbol
+// and decimals from the coin metadata. The sui pool objects can not be found from the pool
+// ids, pass them as poolObjects, they are matched by the coin type of Pool<CoinType>
+func (q *QueryClient) LoadPoolRegistry(ctx context.Context, poolObjects ...sui_types.ObjectID) (*PoolRegistry, error) {
+	var reserves []ReserveInfo
+	if err := q.NewBatch().GetAllReserveInfo(&reserves).Execute(ctx); err != nil {
+		return nil, err
+	}
+	if len(reserves) == 0 {
+		return nil, errors.New("no reserve")
+	}
+	pools := make([][]PoolInfo, len(reserves))
+	batch := q.NewBatch()
+	for i, reserve := range reserves {
+		batch.GetAllPoolLiquidity(reserve.DolaPoolId, &pools[i])
+	}
+	if err := batch.Execute(ctx); err != nil {
+		return nil, err
+	}
+
+	registry := NewPoolRegistry()
+	for i, reserve := range reserves {
+		token := PoolToken{DolaPoolId: reserve.DolaPoolId, PoolAddresses: make(map[uint16]string)}
+		for _, pool := range pools[i] {
+			token.PoolAddresses[pool.DolaChainId] = pool.DolaAddress
+		}
+		if token.CoinType = token.PoolAddresses[DolaChainIdSui]; token.CoinType == "" {
+			// the pool has no token on sui, it can not be used by the lending calls of this package
+			continue
+		}
+		metadata, err := q.contract.client.GetCoinMetadata(ctx, token.CoinType)
+		if err != nil {
+			return nil, fmt.Errorf("get %s metadata: %w", token.CoinType, err)
+		}
+		token.Symbol = metadata.Symbol
+		token.Decimals = int32(metadata.Decimals)
+		if err = registry.Register(token); err != nil {
+			return nil, err
+		}
+	}
+	if len(poolObjects) == 0 {
+		return registry, nil
+	}
+
+	objects, err := q.contract.client.MultiGetObjects(ctx, poolObjects, &types.SuiObjectDataOptions{ShowType: true})
+	if err != nil {
+		return nil, err
+	}
+	if len(objects) != len(poolObjects) {
+		return nil, errors.New("object response count mismatch")
+	}
+	for i, object := range objects {
+		if object.Data == nil || object.Data.Type == nil {
+			return nil, fmt.Errorf("pool object %s not found", poolObjects[i])
+		}
+		if err = registry.setPoolObject(q.contract, poolObjects[i], *object.Data.Type); err != nil {
+			return nil, err
+		}
+	}
+	return registry, nil
+}
+
+// setPoolObject set pool as the sui pool of the token of the coin type of Pool<CoinType>, the
+// pool type must be from the bridge pool package of contract
+func (r *PoolRegistry) setPoolObject(contract *Contract, pool sui_types.ObjectID, poolType string) error {
+	tag, err := parseTypeTag(poolType)
+	if err != nil || tag.Struct == nil || tag.Struct.Name != "Pool" || len(tag.Struct.TypeParams) != 1 {
+		return fmt.Errorf("object %s is not a pool: %s", pool, poolType)
+	}
+	if !contract.samePackage(*contract.bridgePoolPackageId, tag.Struct.Address) {
+		return fmt.Errorf("object %s is not a pool of the bridge pool package: %s", pool, poolType)
+	}
+	token, err := r.TokenByCoinType(formatTypeTag(tag.Struct.TypeParams[0]))
+	if err != nil {
+		return fmt.Errorf("pool object %s: %w", pool, err)
+	}
+	return r.SetPool(token.DolaPoolId, pool)
+}
+
+// LoadPoolRegistry is QueryClient.LoadPoolRegistry
+func (c *Contract) LoadPoolRegistry(ctx context.Context, poolObjects ...sui_types.ObjectID) (*PoolRegistry, error) {
+	return c.QueryClient().LoadPoolRegistry(ctx, poolObjects...)
+}
+
+// WithPools return the calls by symbol of the tokens of registry
+func (c *Contract) WithPools(registry *PoolRegistry) *PoolContract {
+	return &PoolContract{contract: c, registry: registry, callOptions: DefaultPoolCallOptions}
+}
+
+// WithCallOptions return a copy of p using callOptions when a call is given none
+func (p *PoolContract) WithCallOptions(callOptions CallOptions) *PoolContract {
+	copied := *p
+	copied.callOptions = callOptions
+	return &copied
+}
+
+func (p *PoolContract) Registry() *PoolRegistry {
+	return p.registry
+}
+
+// Supply deposit amount of symbol, amount is human readable like "1.5". Only the first
+// callOptions is used, as for all calls of PoolContract
+func (p *PoolContract) Supply(ctx context.Context, signer sui_types.SuiAddress, symbol string, amount string, callOptions ...CallOptions) (*types.TransactionBytes, error) {
+	token, raw, err := p.tokenAmount(symbol, amount)
+	if err != nil {
+		return nil, err
+	}
+	return p.contract.Supply(ctx, signer, []string{token.CoinType}, SupplyArgs{Pool: *token.Pool, DepositAmount: raw}, p.options(callOptions))
+}
+
+func (p *PoolContract) WithdrawLocal(ctx context.Context, signer sui_types.SuiAddress, symbol string, amount string, callOptions ...CallOptions) (*types.TransactionBytes, error) {
+	token, raw, err := p.tokenAmount(symbol, amount)
+	if err != nil {
+		return nil, err
+	}
+	return p.contract.WithdrawLocal(ctx, signer, []string{token.CoinType}, WithdrawArgs{Pool: *token.Pool, Amount: raw}, p.options(callOptions))
+}
+
+func (p *PoolContract) BorrowLocal(ctx context.Context, signer sui_types.SuiAddress, symbol string, amount string, callOptions ...CallOptions) (*types.TransactionBytes, error) {
+	token, raw, err := p.tokenAmount(symbol, amount)
+	if err != nil {
+		return nil, err
+	}
+	return p.contract.BorrowLocal(ctx, signer, []string{token.CoinType}, BorrowArgs{Pool: *token.Pool, Amount: raw}, p.options(callOptions))
+}
+
+func (p *PoolContract) Repay(ctx context.Context, signer sui_types.SuiAddress, symbol string, amount string, callOptions ...CallOptions) (*types.TransactionBytes, error) {
+	token, raw, err := p.tokenAmount(symbol, amount)
+	if err != nil {
+		return nil, err
+	}
+	return p.contract.Repay(ctx, signer, []string{token.CoinType}, RepayArgs{Pool: *token.Pool, RepayAmount: raw}, p.options(callOptions))
+}
+
+// options return the single callOptions passed to a call, the default of p if none
+func (p *PoolContract) options(callOptions []CallOptions) CallOptions {
+	if len(callOptions) > 0 {
+		return callOptions[0]
+	}
+	return p.callOptions
+}
+
+// tokenAmount return the token of symbol with a sui pool and the raw coin amount
+func (p *PoolContract) tokenAmount(symbol string, amount string) (PoolToken, string, error) {
+	token, err := p.registry.TokenBySymbol(symbol)
+	if err != nil {
+		return PoolToken{}, "", err
+	}
+	if token.Pool == nil {
+		return PoolToken{}, "", fmt.Errorf("sui pool object of %s not loaded", symbol)
+	}
+	parsed, err := ParseAmount(amount, token.Decimals)
+	if err != nil {
+		return PoolToken{}, "", err
+	}
+	if parsed.Raw.Sign() == 0 {
+		return PoolToken{}, "", fmt.Errorf("zero amount of %s", symbol)
+	}
+	return token, parsed.RawString(), nil
+}
@@ -0,0 +1,82 @@
+package gosuilending
+
+import (
+	"context"
+	"testing"
+)
+
+func Test_PoolRegistry(t *testing.T) {
+	registry := NewPoolRegistry()
+	AssertNil(registry.Register(PoolToken{
+		DolaPoolId:    devUSDTPoolId,
+		Symbol:        "USDT",
+		Decimals:      6,
+		CoinType:      devUSDTAddress,
+		PoolAddresses: map[uint16]string{DolaChainIdSui: devUSDTAddress},
+	}))
+	AssertNil(registry.Register(PoolToken{DolaPoolId: 2, Symbol: "USDC", Decimals: 6, CoinType: "0xa1::coin::COIN"}))
+	AssertNil(registry.Register(PoolToken{DolaPoolId: 3, Symbol: "usdc", Decimals: 6, CoinType: "0xa2::usdc::USDC"}))
+	if err := registry.Register(PoolToken{DolaPoolId: 4, Symbol: "X", CoinType: "0xa1::coin::COIN"}); err == nil {
+		t.Errorf("Register() should fail on a registered coin type")
+	}
+
+	token, err := registry.TokenByCoinType("0x" + devUSDTAddress)
+	AssertNil(err)
+	if token.DolaPoolId != devUSDTPoolId || token.Symbol != "USDT" {
+		t.Errorf("TokenByCoinType() = %+v", token)
+	}
+	if _, err = registry.TokenBySymbol("USDC"); err == nil {
+		t.Errorf("TokenBySymbol() should fail on an ambiguous symbol")
+	}
+
+	// rename pool 3, USDC is no more ambiguous
+	AssertNil(registry.Register(PoolToken{DolaPoolId: 3, Symbol: "wUSDC", Decimals: 6, CoinType: "0xa2::usdc::USDC"}))
+	token, err = registry.TokenBySymbol("usdc")
+	AssertNil(err)
+	if token.DolaPoolId != 2 {
+		t.Errorf("TokenBySymbol() = %+v", token)
+	}
+
+	contract := getDevContract().WithPools(registry)
+	if _, _, err = contract.tokenAmount("USDT", "1"); err == nil {
+		t.Errorf("tokenAmount() should fail without pool object")
+	}
+	bridgePool := MainnetConfig.BridgePoolPackageId
+	if err = registry.setPoolObject(contract.contract, *toHex("0x11"), bridgePool+"::pool::Pool<0xa3::coin::COIN>"); err == nil {
+		t.Errorf("setPoolObject() should fail on an unknown coin type")
+	}
+	if err = registry.setPoolObject(contract.contract, *toHex("0x11"), "0xb1::pool::Pool<0x"+devUSDTAddress+">"); err == nil {
+		t.Errorf("setPoolObject() should fail on a pool of another package")
+	}
+	AssertNil(registry.setPoolObject(contract.contract, *toHex("0x11"), bridgePool+"::pool::Pool<0x"+devUSDTAddress+">"))
+	token, raw, err := contract.tokenAmount("usdt", "1.5")
+	AssertNil(err)
+	if *token.Pool != *toHex("0x11") || raw != "1500000" {
+		t.Errorf("tokenAmount() = %s, %s", token.Pool, raw)
+	}
+	if _, _, err = contract.tokenAmount("USDT", "1.0000001"); err == nil {
+		t.Errorf("tokenAmount() should fail on extra decimals")
+	}
+	if options := contract.options(nil); !options.AutoGasBudget {
+		t.Errorf("default call options = %+v", options)
+	}
+	if options := contract.WithCallOptions(CallOptions{GasBudget: 1}).options(nil); options.GasBudget != 1 || options.AutoGasBudget {
+		t.Errorf("WithCallOptions() options = %+v", options)
+	}
+	if decimals, err := registry.PoolDecimals().Decimals(2); err != nil || decimals != 6 {
+		t.Errorf("PoolDecimals() = %d, %v", decimals, err)
+	}
+}
+
+func TestContract_LoadPoolRegistry(t *testing.T) {
+	registry, err := getDevContract().LoadPoolRegistry(context.Background())
+	AssertNil(err)
+	for _, token := range registry.Tokens() {
+		t.Logf("%d %s %d %s %v", token.DolaPoolId, token.Symbol, token.Decimals, token.CoinType, token.PoolAddresses)
+	}
+	token, err := registry.Token(getUSDTPoolId())
+	AssertNil(err)
+	if _, err = registry.TokenByCoinType(getUSDTAddress()); err != nil || token.Decimals == 0 {
+		t.Errorf("USDT token = %+v, %v", token, err)
+	}
+}
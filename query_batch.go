@@ -0,0 +1,123 @@
+package gosuilending
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+)
+
+// maxBatchInspects is the most dev inspect requests a batch send at the same time
+const maxBatchInspects = 8
+
+// QueryBatch run many interfaces::get_* calls together. Results are written to the pointers
+// given to each step after Execute. e.g.
+//
+//	var info *UserLendingInfo
+//	var prices []DolaTokenPrice
+//	err := queryClient.NewBatch().
+//		GetUserLendingInfo(userId, &info).
+//		GetAllOraclePrice(&prices).
+//		Execute(ctx)
+type QueryBatch struct {
+	client  *QueryClient
+	queries []query
+}
+
+func (q *QueryClient) NewBatch() *QueryBatch {
+	return &QueryBatch{client: q}
+}
+
+// Execute inspect every query by its own dev inspect, concurrently. Dev inspect does not tell
+// which command of a transaction emitted an event, one transaction per query is the only way to
+// know the event of a query. The first failed query, by order of the batch, is returned
+func (b *QueryBatch) Execute(ctx context.Context) error {
+	if len(b.queries) == 0 {
+		return errors.New("empty query batch")
+	}
+	errs := make([]error, len(b.queries))
+	limit := make(chan struct{}, maxBatchInspects)
+	var wg sync.WaitGroup
+	for i := range b.queries {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			limit <- struct{}{}
+			defer func() { <-limit }()
+			errs[i] = b.client.inspect(ctx, b.queries[i])
+		}(i)
+	}
+	wg.Wait()
+	for i, err := range errs {
+		if err != nil {
+			return fmt.Errorf("query %d %s: %w", i, b.queries[i].call.function, err)
+		}
+	}
+	return nil
+}
+
+func (b *QueryBatch) add(qry query) *QueryBatch {
+	b.queries = append(b.queries, qry)
+	return b
+}
+
+func (b *QueryBatch) GetDolaTokenLiquidity(dolaPoolId uint16, liquidity **big.Int) *QueryBatch {
+	return b.add(b.client.contract.dolaTokenLiquidityQuery(dolaPoolId, liquidity))
+}
+
+func (b *QueryBatch) GetAppTokenLiquidity(appId uint16, dolaPoolId uint16, liquidity **big.Int) *QueryBatch {
+	return b.add(b.client.contract.appTokenLiquidityQuery(appId, dolaPoolId, liquidity))
+}
+
+func (b *QueryBatch) GetPoolLiquidity(dolaChainId uint16, poolAddress string, liquidity **big.Int) *QueryBatch {
+	return b.add(b.client.contract.poolLiquidityQuery(dolaChainId, poolAddress, liquidity))
+}
+
+func (b *QueryBatch) GetAllPoolLiquidity(dolaPoolId uint16, poolInfos *[]PoolInfo) *QueryBatch {
+	return b.add(b.client.contract.allPoolLiquidityQuery(dolaPoolId, poolInfos))
+}
+
+func (b *QueryBatch) GetUserTokenDebt(dolaUserId string, dolaPoolId uint16, debtAmount **big.Int, debtValue **big.Int) *QueryBatch {
+	return b.add(b.client.contract.userTokenDebtQuery(dolaUserId, dolaPoolId, debtAmount, debtValue))
+}
+
+func (b *QueryBatch) GetUserCollateral(dolaUserId string, dolaPoolId uint16, collateral *CollateralItem) *QueryBatch {
+	return b.add(b.client.contract.userCollateralQuery(dolaUserId, dolaPoolId, collateral))
+}
+
+func (b *QueryBatch) GetAllReserveInfo(reserveInfos *[]ReserveInfo) *QueryBatch {
+	return b.add(b.client.contract.allReserveInfoQuery(reserveInfos))
+}
+
+func (b *QueryBatch) GetReserveInfo(dolaPoolId uint16, reserveInfo **ReserveInfo) *QueryBatch {
+	return b.add(b.client.contract.reserveInfoQuery(dolaPoolId, reserveInfo))
+}
+
+func (b *QueryBatch) GetUserAllowedBorrow(dolaUserId string, borrowPoolId uint16, amount **big.Int) *QueryBatch {
+	return b.add(b.client.contract.userAllowedBorrowQuery(dolaUserId, borrowPoolId, amount))
+}
+
+func (b *QueryBatch) GetUserLendingInfo(dolaUserId string, userLendingInfo **UserLendingInfo) *QueryBatch {
+	return b.add(b.client.contract.userLendingInfoQuery(dolaUserId, userLendingInfo))
+}
+
+func (b *QueryBatch) GetOraclePrice(dolaPoolId uint16, dolaTokenPrice *DolaTokenPrice) *QueryBatch {
+	return b.add(b.client.contract.oraclePriceQuery(dolaPoolId, dolaTokenPrice))
+}
+
+func (b *QueryBatch) GetAllOraclePrice(prices *[]DolaTokenPrice) *QueryBatch {
+	return b.add(b.client.contract.allOraclePriceQuery(prices))
+}
+
+func (b *QueryBatch) GetDolaUserId(dolaChainId uint16, user string, userId *string) *QueryBatch {
+	return b.add(b.client.contract.dolaUserIdQuery(dolaChainId, user, userId))
+}
+
+func (b *QueryBatch) GetDolaUserAddresses(dolaUserId string, dolaUserAddresses *[]DolaUserAddress) *QueryBatch {
+	return b.add(b.client.contract.dolaUserAddressesQuery(dolaUserId, dolaUserAddresses))
+}
+
+func (b *QueryBatch) GetUserHealthFactor(dolaUserId string, healthFactor **big.Int) *QueryBatch {
+	return b.add(b.client.contract.userHealthFactorQuery(dolaUserId, healthFactor))
+}
@@ -0,0 +1,103 @@
+package gosuilending
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/coming-chat/go-sui/v2/client"
+	"github.com/coming-chat/go-sui/v2/sui_types"
+)
+
+func Test_QueryBatch_Execute(t *testing.T) {
+	var inspects int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Id     any               `json:"id"`
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		id, _ := json.Marshal(req.Id)
+		var txBytes string
+		if req.Method != "sui_devInspectTransactionBlock" || len(req.Params) < 2 || json.Unmarshal(req.Params[1], &txBytes) != nil {
+			_, _ = w.Write([]byte(`{"jsonrpc": "2.0", "id": ` + string(id) + `, "error": {"code": -32601, "message": "unexpected ` + req.Method + `"}}`))
+			return
+		}
+		atomic.AddInt32(&inspects, 1)
+		tx, _ := base64.StdEncoding.DecodeString(txBytes)
+		// the event of the other query in the same transaction would be taken for this one
+		healthFactor := bytes.Contains(tx, []byte("get_user_health_factor"))
+		allowedBorrow := bytes.Contains(tx, []byte("get_user_allowed_borrow"))
+		parsedJson := `{"health_factor": "7"}`
+		if allowedBorrow {
+			parsedJson = `{"borrow_amount": "9", "reason": ""}`
+		}
+		if healthFactor == allowedBorrow {
+			parsedJson = `{}`
+		}
+		_, _ = w.Write([]byte(`{"jsonrpc": "2.0", "id": ` + string(id) + `, "result": {
+			"effects": {"messageVersion": "v1", "status": {"status": "success"}},
+			"events": [{"id": {"txDigest": "8wdDDRaQrHTuwVRhXpERgW3VXUSddcAfkKjkoe3m7Upb", "eventSeq": "0"}, "packageId": "0x1",
+				"transactionModule": "interfaces", "sender": "0x0", "type": "0x1::interfaces::Event", "bcs": "", "parsedJson": ` + parsedJson + `}]
+		}}`))
+	}))
+	defer server.Close()
+	client, err := client.Dial(server.URL)
+	AssertNil(err)
+
+	c := getDevContract()
+	c.client = client
+	c.sharedObjects = map[sui_types.ObjectID]sui_types.SequenceNumber{*c.poolManagerInfo: 1, *c.storage: 1, *c.priceOracle: 1}
+	ref := func(name string) moveNormalizedType {
+		return moveNormalizedType{Reference: &moveNormalizedType{Struct: &moveNormalizedStruct{Address: "0x1", Module: "m", Name: name}}}
+	}
+	c.moveFunctions = map[string]*moveNormalizedFunction{
+		c.externalInterfacePackageId.String() + "::interfaces::get_user_health_factor": {
+			Parameters: []moveNormalizedType{ref("Storage"), ref("PriceOracle"), {Primitive: "U64"}},
+		},
+		c.externalInterfacePackageId.String() + "::interfaces::get_user_allowed_borrow": {
+			Parameters: []moveNormalizedType{ref("PoolManagerInfo"), ref("Storage"), ref("PriceOracle"), {Primitive: "U64"}, {Primitive: "U16"}},
+		},
+	}
+
+	var healthFactor, allowedBorrow *big.Int
+	err = c.QueryClient().NewBatch().
+		GetUserHealthFactor("1", &healthFactor).
+		GetUserAllowedBorrow("1", 2, &allowedBorrow).
+		Execute(context.Background())
+	AssertNil(err)
+	if inspects != 2 {
+		t.Errorf("inspects = %d, want one per query", inspects)
+	}
+	if healthFactor == nil || healthFactor.Int64() != 7 || allowedBorrow == nil || allowedBorrow.Int64() != 9 {
+		t.Errorf("Execute() health factor = %v, allowed borrow = %v", healthFactor, allowedBorrow)
+	}
+}
+
+func TestQueryBatch_Execute(t *testing.T) {
+	var (
+		lendingInfo  *UserLendingInfo
+		reserveInfos []ReserveInfo
+		prices       []DolaTokenPrice
+		addresses    []DolaUserAddress
+	)
+	err := getDevContract().QueryClient().NewBatch().
+		GetUserLendingInfo(getUserDolaId(), &lendingInfo).
+		GetAllReserveInfo(&reserveInfos).
+		GetAllOraclePrice(&prices).
+		GetDolaUserAddresses(getUserDolaId(), &addresses).
+		Execute(context.Background())
+	if err != nil {
+		t.Fatalf("QueryBatch.Execute() error = %v", err)
+	}
+	if lendingInfo == nil || len(reserveInfos) == 0 || len(prices) == 0 || len(addresses) == 0 {
+		t.Errorf("QueryBatch.Execute() missing results")
+	}
+}
@@ -0,0 +1,135 @@
+package gosuilending
+
+import (
+	"context"
+	"errors"
+	"math/big"
+
+	"github.com/coming-chat/go-sui/v2/client"
+	"github.com/coming-chat/go-sui/v2/sui_types"
+	"github.com/coming-chat/go-sui/v2/types"
+	"github.com/fardream/go-bcs/bcs"
+)
+
+// QueryClient is the read only side of Contract. Queries are sent by dev inspect,
+// neither a gas object nor a funded signer is needed
+type QueryClient struct {
+	contract *Contract
+}
+
+func NewQueryClient(client *client.Client, config ContractConfig) (*QueryClient, error) {
+	contract, err := NewContract(client, config)
+	if err != nil {
+		return nil, err
+	}
+	return &QueryClient{contract: contract}, nil
+}
+
+// QueryClient return a read only client sharing the object caches of c
+func (c *Contract) QueryClient() *QueryClient {
+	return &QueryClient{contract: c}
+}
+
+func (q *QueryClient) inspect(ctx context.Context, qry query) error {
+	result, err := q.devInspect(ctx, []moveCall{qry.call})
+	if err != nil {
+		return err
+	}
+	return parseLastEvent(result.Effects.Data, result.Events, qry.parseEvent)
+}
+
+func (q *QueryClient) devInspect(ctx context.Context, calls []moveCall) (*types.DevInspectResults, error) {
+	pt, err := q.contract.programmableTransaction(ctx, calls)
+	if err != nil {
+		return nil, err
+	}
+	txBytes, err := bcs.Marshal(sui_types.TransactionKind{ProgrammableTransaction: &pt})
+	if err != nil {
+		return nil, err
+	}
+	result, err := q.contract.client.DevInspectTransactionBlock(ctx, sui_types.SuiAddress{}, txBytes, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	if result.Error != nil {
+		return nil, errors.New(*result.Error)
+	}
+	return result, nil
+}
+
+func (q *QueryClient) GetDolaTokenLiquidity(ctx context.Context, dolaPoolId uint16) (liquidity *big.Int, err error) {
+	err = q.inspect(ctx, q.contract.dolaTokenLiquidityQuery(dolaPoolId, &liquidity))
+	return
+}
+
+func (q *QueryClient) GetAppTokenLiquidity(ctx context.Context, appId uint16, dolaPoolId uint16) (liquidity *big.Int, err error) {
+	err = q.inspect(ctx, q.contract.appTokenLiquidityQuery(appId, dolaPoolId, &liquidity))
+	return
+}
+
+func (q *QueryClient) GetPoolLiquidity(ctx context.Context, dolaChainId uint16, poolAddress string) (liquidity *big.Int, err error) {
+	err = q.inspect(ctx, q.contract.poolLiquidityQuery(dolaChainId, poolAddress, &liquidity))
+	return
+}
+
+func (q *QueryClient) GetAllPoolLiquidity(ctx context.Context, dolaPoolId uint16) (poolInfos []PoolInfo, err error) {
+	err = q.inspect(ctx, q.contract.allPoolLiquidityQuery(dolaPoolId, &poolInfos))
+	return
+}
+
+func (q *QueryClient) GetUserTokenDebt(ctx context.Context, dolaUserId string, dolaPoolId uint16) (debtAmount *big.Int, debtValue *big.Int, err error) {
+	err = q.inspect(ctx, q.contract.userTokenDebtQuery(dolaUserId, dolaPoolId, &debtAmount, &debtValue))
+	return
+}
+
+func (q *QueryClient) GetUserCollateral(ctx context.Context, dolaUserId string, dolaPoolId uint16) (collateral CollateralItem, err error) {
+	err = q.inspect(ctx, q.contract.userCollateralQuery(dolaUserId, dolaPoolId, &collateral))
+	return
+}
+
+func (q *QueryClient) GetAllReserveInfo(ctx context.Context) (reserveInfos []ReserveInfo, err error) {
+	err = q.inspect(ctx, q.contract.allReserveInfoQuery(&reserveInfos))
+	return
+}
+
+func (q *QueryClient) GetReserveInfo(ctx context.Context, dolaPoolId uint16) (reserveInfo *ReserveInfo, err error) {
+	err = q.inspect(ctx, q.contract.reserveInfoQuery(dolaPoolId, &reserveInfo))
+	return
+}
+
+func (q *QueryClient) GetUserAllowedBorrow(ctx context.Context, dolaUserId string, borrowPoolId uint16) (amount *big.Int, err error) {
+	err = q.inspect(ctx, q.contract.userAllowedBorrowQuery(dolaUserId, borrowPoolId, &amount))
+	return
+}
+
+func (q *QueryClient) GetUserLendingInfo(ctx context.Context, dolaUserId string) (userLendingInfo *UserLendingInfo, err error) {
+	err = q.inspect(ctx, q.contract.userLendingInfoQuery(dolaUserId, &userLendingInfo))
+	return
+}
+
+func (q *QueryClient) GetOraclePrice(ctx context.Context, dolaPoolId uint16) (dolaTokenPrice DolaTokenPrice, err error) {
+	err = q.inspect(ctx, q.contract.oraclePriceQuery(dolaPoolId, &dolaTokenPrice))
+	return
+}
+
+func (q *QueryClient) GetAllOraclePrice(ctx context.Context) (prices []DolaTokenPrice, err error) {
+	err = q.inspect(ctx, q.contract.allOraclePriceQuery(&prices))
+	return
+}
+
+// GetDolaUserId return dola_user_id for (dola_chain_id, address) pair
+// if not exist, an error return
+func (q *QueryClient) GetDolaUserId(ctx context.Context, dolaChainId uint16, user string) (userId string, err error) {
+	err = q.inspect(ctx, q.contract.dolaUserIdQuery(dolaChainId, user, &userId))
+	return
+}
+
+func (q *QueryClient) GetDolaUserAddresses(ctx context.Context, dolaUserId string) (dolaUserAddresses []DolaUserAddress, err error) {
+	err = q.inspect(ctx, q.contract.dolaUserAddressesQuery(dolaUserId, &dolaUserAddresses))
+	return
+}
+
+func (q *QueryClient) GetUserHealthFactor(ctx context.Context, dolaUserId string) (healthFactor *big.Int, err error) {
+	err = q.inspect(ctx, q.contract.userHealthFactorQuery(dolaUserId, &healthFactor))
+	return
+}
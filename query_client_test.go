@@ -0,0 +1,24 @@
+package gosuilending
+
+import (
+	"context"
+	"testing"
+)
+
+func TestQueryClient_GetAllOraclePrice(t *testing.T) {
+	q := getDevContract().QueryClient()
+	prices, err := q.GetAllOraclePrice(context.Background())
+	if err != nil {
+		t.Fatalf("QueryClient.GetAllOraclePrice() error = %v", err)
+	}
+	if len(prices) == 0 {
+		t.Errorf("QueryClient.GetAllOraclePrice() return no price")
+	}
+}
+
+func TestQueryClient_GetUserLendingInfo(t *testing.T) {
+	q := getDevContract().QueryClient()
+	if _, err := q.GetUserLendingInfo(context.Background(), getUserDolaId()); err != nil {
+		t.Errorf("QueryClient.GetUserLendingInfo() error = %v", err)
+	}
+}
@@ -0,0 +1,247 @@
+package gosuilending
+
+import (
+	"fmt"
+	"math/big"
+)
+
+var maxU256 = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+
+// MaxHealthFactor is the health factor of a user without debt, max u256 as the contract
+var MaxHealthFactor = new(big.Int).Set(maxU256)
+
+var halfRay = new(big.Int).Rsh(rayUnit, 1)
+
+type (
+	// RiskEngine reproduce the health math of lending core offline, from the reserves and prices
+	// returned by GetAllReserveInfo and GetAllOraclePrice
+	RiskEngine struct {
+		reserves map[uint16]ReserveInfo
+		prices   map[uint16]DolaTokenPrice
+	}
+
+	// RiskReport is the health state of a user, values are 1e8 usd
+	RiskReport struct {
+		TotalCollateralValue  *big.Int
+		TotalDebtValue        *big.Int
+		HealthCollateralValue *big.Int // sum of collateral value * collateral coefficient
+		HealthLoanValue       *big.Int // sum of debt value * borrow coefficient
+		HealthFactor          *big.Int // 1e27 -> 1.0, MaxHealthFactor without debt
+	}
+)
+
+func NewRiskEngine(reserves []ReserveInfo, prices []DolaTokenPrice) *RiskEngine {
+	e := &RiskEngine{
+		reserves: make(map[uint16]ReserveInfo, len(reserves)),
+		prices:   make(map[uint16]DolaTokenPrice, len(prices)),
+	}
+	for _, reserve := range reserves {
+		e.reserves[reserve.DolaPoolId] = reserve
+	}
+	for _, price := range prices {
+		e.prices[price.DolaPoolId] = price
+	}
+	return e
+}
+
+// Evaluate compute the health of a user from its collateral and debt amounts, the values in
+// lendingInfo are not used
+func (e *RiskEngine) Evaluate(lendingInfo *UserLendingInfo) (*RiskReport, error) {
+	report := &RiskReport{
+		TotalCollateralValue:  big.NewInt(0),
+		TotalDebtValue:        big.NewInt(0),
+		HealthCollateralValue: big.NewInt(0),
+		HealthLoanValue:       big.NewInt(0),
+	}
+	for _, collateral := range lendingInfo.CollateralInfos {
+		value, err := e.value(collateral.DolaPoolId, collateral.CollateralAmount)
+		if err != nil {
+			return nil, err
+		}
+		coefficient, err := e.collateralCoefficient(collateral.DolaPoolId)
+		if err != nil {
+			return nil, err
+		}
+		report.TotalCollateralValue.Add(report.TotalCollateralValue, value)
+		report.HealthCollateralValue.Add(report.HealthCollateralValue, rayMul(value, coefficient))
+	}
+	for _, debt := range lendingInfo.DebtInfos {
+		value, err := e.value(debt.DolaPoolId, debt.DebtAmount)
+		if err != nil {
+			return nil, err
+		}
+		coefficient, err := e.borrowCoefficient(debt.DolaPoolId)
+		if err != nil {
+			return nil, err
+		}
+		report.TotalDebtValue.Add(report.TotalDebtValue, value)
+		report.HealthLoanValue.Add(report.HealthLoanValue, rayMul(value, coefficient))
+	}
+	report.HealthFactor = healthFactor(report.HealthCollateralValue, report.HealthLoanValue)
+	return report, nil
+}
+
+// HealthFactor is the same as GetUserHealthFactor
+func (e *RiskEngine) HealthFactor(lendingInfo *UserLendingInfo) (*big.Int, error) {
+	report, err := e.Evaluate(lendingInfo)
+	if err != nil {
+		return nil, err
+	}
+	return report.HealthFactor, nil
+}
+
+// AllowedBorrow is the same as GetUserAllowedBorrow, the amount of borrowPoolId the user can
+// borrow before its health factor fall to 1
+func (e *RiskEngine) AllowedBorrow(lendingInfo *UserLendingInfo, borrowPoolId uint16) (*big.Int, error) {
+	report, err := e.Evaluate(lendingInfo)
+	if err != nil {
+		return nil, err
+	}
+	price, err := e.price(borrowPoolId)
+	if err != nil {
+		return nil, err
+	}
+	coefficient, err := e.borrowCoefficient(borrowPoolId)
+	if err != nil {
+		return nil, err
+	}
+	if report.HealthCollateralValue.Cmp(report.HealthLoanValue) <= 0 {
+		return big.NewInt(0), nil
+	}
+	available := new(big.Int).Sub(report.HealthCollateralValue, report.HealthLoanValue)
+	return valueToAmount(rayDiv(available, coefficient), price), nil
+}
+
+// MaxWithdraw return the amount of dolaPoolId the user can withdraw keeping its health factor
+// at least 1. All supply can be withdrawn without debt
+func (e *RiskEngine) MaxWithdraw(lendingInfo *UserLendingInfo, dolaPoolId uint16) (*big.Int, error) {
+	collateral := findCollateral(lendingInfo, dolaPoolId)
+	if collateral == nil {
+		return big.NewInt(0), nil
+	}
+	report, err := e.Evaluate(lendingInfo)
+	if err != nil {
+		return nil, err
+	}
+	if report.HealthLoanValue.Sign() == 0 {
+		return new(big.Int).Set(collateral.CollateralAmount), nil
+	}
+	if report.HealthCollateralValue.Cmp(report.HealthLoanValue) <= 0 {
+		return big.NewInt(0), nil
+	}
+	price, err := e.price(dolaPoolId)
+	if err != nil {
+		return nil, err
+	}
+	coefficient, err := e.collateralCoefficient(dolaPoolId)
+	if err != nil {
+		return nil, err
+	}
+	if coefficient.Sign() == 0 {
+		// the collateral does not count for health, all can be withdrawn
+		return new(big.Int).Set(collateral.CollateralAmount), nil
+	}
+	available := new(big.Int).Sub(report.HealthCollateralValue, report.HealthLoanValue)
+	amount := valueToAmount(rayDiv(available, coefficient), price)
+	return minBigInt(amount, collateral.CollateralAmount), nil
+}
+
+// LiquidationPrice return the price of collateralPoolId at which the health factor fall to 1,
+// other prices unchanged. The price has the decimal of the oracle price, nil if no price of the
+// collateral can make the user liquidatable
+func (e *RiskEngine) LiquidationPrice(lendingInfo *UserLendingInfo, collateralPoolId uint16) (*big.Int, error) {
+	collateral := findCollateral(lendingInfo, collateralPoolId)
+	if collateral == nil || collateral.CollateralAmount.Sign() == 0 {
+		return nil, fmt.Errorf("no collateral in pool %d", collateralPoolId)
+	}
+	report, err := e.Evaluate(lendingInfo)
+	if err != nil {
+		return nil, err
+	}
+	price, err := e.price(collateralPoolId)
+	if err != nil {
+		return nil, err
+	}
+	coefficient, err := e.collateralCoefficient(collateralPoolId)
+	if err != nil {
+		return nil, err
+	}
+	value, err := e.value(collateralPoolId, collateral.CollateralAmount)
+	if err != nil {
+		return nil, err
+	}
+	others := new(big.Int).Sub(report.HealthCollateralValue, rayMul(value, coefficient))
+	if report.HealthLoanValue.Cmp(others) <= 0 || coefficient.Sign() == 0 {
+		return nil, nil
+	}
+	// others + amount * P / 10^decimal * coefficient = loan
+	needed := new(big.Int).Sub(report.HealthLoanValue, others)
+	result := new(big.Int).Mul(needed, pow10(price.Decimal))
+	result.Mul(result, rayUnit)
+	return result.Quo(result, new(big.Int).Mul(collateral.CollateralAmount, coefficient)), nil
+}
+
+func (e *RiskEngine) value(dolaPoolId uint16, amount *big.Int) (*big.Int, error) {
+	price, err := e.price(dolaPoolId)
+	if err != nil {
+		return nil, err
+	}
+	return amountToValue(amount, price), nil
+}
+
+func (e *RiskEngine) price(dolaPoolId uint16) (DolaTokenPrice, error) {
+	price, ok := e.prices[dolaPoolId]
+	if !ok {
+		return DolaTokenPrice{}, fmt.Errorf("price of pool %d not found", dolaPoolId)
+	}
+	if price.Price == nil || price.Price.Sign() == 0 {
+		return price, fmt.Errorf("invalid price of pool %d", dolaPoolId)
+	}
+	return price, nil
+}
+
+func (e *RiskEngine) collateralCoefficient(dolaPoolId uint16) (*big.Int, error) {
+	reserve, ok := e.reserves[dolaPoolId]
+	if !ok || reserve.CollateralCoefficient == nil {
+		return nil, fmt.Errorf("collateral coefficient of pool %d not found", dolaPoolId)
+	}
+	return reserve.CollateralCoefficient, nil
+}
+
+func (e *RiskEngine) borrowCoefficient(dolaPoolId uint16) (*big.Int, error) {
+	reserve, ok := e.reserves[dolaPoolId]
+	if !ok || reserve.BorrowCoefficient == nil || reserve.BorrowCoefficient.Sign() == 0 {
+		return nil, fmt.Errorf("borrow coefficient of pool %d not found", dolaPoolId)
+	}
+	return reserve.BorrowCoefficient, nil
+}
+
+func findCollateral(lendingInfo *UserLendingInfo, dolaPoolId uint16) *CollateralItem {
+	for i := range lendingInfo.CollateralInfos {
+		if lendingInfo.CollateralInfos[i].DolaPoolId == dolaPoolId {
+			return &lendingInfo.CollateralInfos[i]
+		}
+	}
+	return nil
+}
+
+func healthFactor(healthCollateralValue, healthLoanValue *big.Int) *big.Int {
+	if healthLoanValue.Sign() == 0 {
+		return new(big.Int).Set(MaxHealthFactor)
+	}
+	return rayDiv(healthCollateralValue, healthLoanValue)
+}
+
+// rayMul is (a * b + ray / 2) / ray, the same rounding as the contract ray_math
+func rayMul(a, b *big.Int) *big.Int {
+	result := new(big.Int).Mul(a, b)
+	result.Add(result, halfRay)
+	return result.Quo(result, rayUnit)
+}
+
+// rayDiv is (a * ray + b / 2) / b
+func rayDiv(a, b *big.Int) *big.Int {
+	result := new(big.Int).Mul(a, rayUnit)
+	result.Add(result, new(big.Int).Rsh(b, 1))
+	return result.Quo(result, b)
+}
@@ -0,0 +1,122 @@
+package gosuilending
+
+import (
+	"context"
+	"math/big"
+	"testing"
+)
+
+func Test_RiskEngine(t *testing.T) {
+	ray := func(numerator, denominator int64) *big.Int {
+		r := new(big.Int).Mul(rayUnit, big.NewInt(numerator))
+		return r.Quo(r, big.NewInt(denominator))
+	}
+	reserves := []ReserveInfo{
+		{DolaPoolId: 0, CollateralCoefficient: ray(8, 10), BorrowCoefficient: ray(1, 1)},
+		{DolaPoolId: 1, CollateralCoefficient: ray(9, 10), BorrowCoefficient: ray(1, 1)},
+	}
+	prices := []DolaTokenPrice{
+		{DolaPoolId: 0, Decimal: 2, Price: big.NewInt(3000000)}, // 30000
+		{DolaPoolId: 1, Decimal: 2, Price: big.NewInt(100)},     // 1
+	}
+	lendingInfo := &UserLendingInfo{
+		CollateralInfos: []CollateralItem{{DolaPoolId: 0, CollateralAmount: big.NewInt(1e8)}}, // 30000$
+		DebtInfos:       []DebtItem{{DolaPoolId: 1, DebtAmount: big.NewInt(12000e8)}},         // 12000$
+	}
+	engine := NewRiskEngine(reserves, prices)
+
+	report, err := engine.Evaluate(lendingInfo)
+	AssertNil(err)
+	// 30000 * 0.8 / 12000 = 2
+	if report.HealthFactor.Cmp(ray(2, 1)) != 0 || report.HealthCollateralValue.Cmp(big.NewInt(24000e8)) != 0 {
+		t.Errorf("Evaluate() = %+v", report)
+	}
+
+	allowed, err := engine.AllowedBorrow(lendingInfo, 1)
+	AssertNil(err)
+	if allowed.Cmp(big.NewInt(12000e8)) != 0 {
+		t.Errorf("AllowedBorrow() = %v", allowed)
+	}
+
+	// 12000$ health value is 0.5 btc
+	withdraw, err := engine.MaxWithdraw(lendingInfo, 0)
+	AssertNil(err)
+	if withdraw.Cmp(big.NewInt(5e7)) != 0 {
+		t.Errorf("MaxWithdraw() = %v", withdraw)
+	}
+
+	// 1 btc * P * 0.8 = 12000 -> P = 15000
+	price, err := engine.LiquidationPrice(lendingInfo, 0)
+	AssertNil(err)
+	if price.Cmp(big.NewInt(1500000)) != 0 {
+		t.Errorf("LiquidationPrice() = %v", price)
+	}
+
+	lendingInfo.DebtInfos = nil
+	report, err = engine.Evaluate(lendingInfo)
+	AssertNil(err)
+	if report.HealthFactor.Cmp(MaxHealthFactor) != 0 {
+		t.Errorf("health factor without debt = %v", report.HealthFactor)
+	}
+	if price, err = engine.LiquidationPrice(lendingInfo, 0); err != nil || price != nil {
+		t.Errorf("LiquidationPrice() = %v, %v, want nil", price, err)
+	}
+
+	// a borrow coefficient of 1.25 weight the debt by 1.25
+	reserves[1].BorrowCoefficient = ray(5, 4)
+	lendingInfo.DebtInfos = []DebtItem{{DolaPoolId: 1, DebtAmount: big.NewInt(12000e8)}}
+	engine = NewRiskEngine(reserves, prices)
+	report, err = engine.Evaluate(lendingInfo)
+	AssertNil(err)
+	// 30000 * 0.8 / (12000 * 1.25) = 1.6
+	if report.HealthLoanValue.Cmp(big.NewInt(15000e8)) != 0 || report.HealthFactor.Cmp(ray(8, 5)) != 0 {
+		t.Errorf("Evaluate() with borrow coefficient = %+v", report)
+	}
+	// (24000 - 15000) / 1.25 = 7200
+	if allowed, err = engine.AllowedBorrow(lendingInfo, 1); err != nil || allowed.Cmp(big.NewInt(7200e8)) != 0 {
+		t.Errorf("AllowedBorrow() with borrow coefficient = %v, %v", allowed, err)
+	}
+	// (24000 - 15000) / 0.8 / 30000 = 0.375 btc
+	if withdraw, err = engine.MaxWithdraw(lendingInfo, 0); err != nil || withdraw.Cmp(big.NewInt(375e5)) != 0 {
+		t.Errorf("MaxWithdraw() with borrow coefficient = %v, %v", withdraw, err)
+	}
+	// 1 btc * P * 0.8 = 15000 -> P = 18750
+	if price, err = engine.LiquidationPrice(lendingInfo, 0); err != nil || price.Cmp(big.NewInt(1875000)) != 0 {
+		t.Errorf("LiquidationPrice() with borrow coefficient = %v, %v", price, err)
+	}
+}
+
+func TestRiskEngine_CrossCheck(t *testing.T) {
+	ctx := context.Background()
+	q := getDevContract().QueryClient()
+	userId := getUserDolaId()
+
+	var (
+		lendingInfo  *UserLendingInfo
+		reserves     []ReserveInfo
+		prices       []DolaTokenPrice
+		healthFactor *big.Int
+		allowed      *big.Int
+	)
+	err := q.NewBatch().
+		GetUserLendingInfo(userId, &lendingInfo).
+		GetAllReserveInfo(&reserves).
+		GetAllOraclePrice(&prices).
+		GetUserHealthFactor(userId, &healthFactor).
+		GetUserAllowedBorrow(userId, getUSDTPoolId(), &allowed).
+		Execute(ctx)
+	AssertNil(err)
+
+	engine := NewRiskEngine(reserves, prices)
+	localHealthFactor, err := engine.HealthFactor(lendingInfo)
+	AssertNil(err)
+	if localHealthFactor.Cmp(healthFactor) != 0 {
+		t.Errorf("health factor = %v, on chain %v", localHealthFactor, healthFactor)
+	}
+	localAllowed, err := engine.AllowedBorrow(lendingInfo, getUSDTPoolId())
+	AssertNil(err)
+	// the contract may also cap the amount by the pool liquidity
+	if localAllowed.Cmp(allowed) < 0 {
+		t.Errorf("allowed borrow = %v, on chain %v", localAllowed, allowed)
+	}
+}
@@ -0,0 +1,296 @@
+package gosuilending
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/coming-chat/go-sui/v2/sui_types"
+)
+
+const (
+	SimulateOffline SimulationMode = iota // estimate from reserve coefficients and oracle prices
+	SimulateDryRun                        // dry run the real transaction with a lending info query after it
+)
+
+// dolaDecimal is the decimal of all dola amounts
+const dolaDecimal = 8
+
+// DefaultHealthThreshold is used when SimulateActionArgs.HealthThreshold is nil, 1.1e27 -> 1.1
+var DefaultHealthThreshold = new(big.Int).Quo(new(big.Int).Mul(rayUnit, big.NewInt(11)), big.NewInt(10))
+
+type (
+	SimulationMode int
+
+	SimulateActionArgs struct {
+		DolaUserId      string
+		CallType        int // CallTypeSupply, CallTypeWithdraw, CallTypeBorrow or CallTypeRepay
+		DolaPoolId      uint16
+		Amount          *big.Int // 100000000 -> 100000000/1e8 = 1
+		Mode            SimulationMode
+		HealthThreshold *big.Int // 1e27 -> 1.0, DefaultHealthThreshold if nil
+
+		// only for SimulateOffline, the apys of the pool are recomputed after the action if set
+		RateModels InterestRateModels
+
+		// only for SimulateDryRun, the local call is built on Pool with the coin type TypeArgs[0]
+		TypeArgs []string
+		Pool     sui_types.ObjectID
+	}
+
+	SimulationResult struct {
+		Before         *UserLendingInfo
+		After          *UserLendingInfo
+		BelowThreshold bool   // after health factor below HealthThreshold
+		Warning        string // empty if not BelowThreshold
+	}
+)
+
+// SimulateAction tell what a supply, withdraw, borrow or repay would do to the user. The dry run
+// mode need a signer able to pay the real transaction
+func (c *Contract) SimulateAction(ctx context.Context, signer sui_types.SuiAddress, simulateArgs SimulateActionArgs, callOptions CallOptions) (*SimulationResult, error) {
+	var (
+		before   *UserLendingInfo
+		reserves []ReserveInfo
+		prices   []DolaTokenPrice
+	)
+	batch := c.QueryClient().NewBatch().GetUserLendingInfo(simulateArgs.DolaUserId, &before)
+	if simulateArgs.Mode == SimulateOffline {
+		batch.GetAllReserveInfo(&reserves).GetAllOraclePrice(&prices)
+	}
+	if err := batch.Execute(ctx); err != nil {
+		return nil, err
+	}
+
+	switch simulateArgs.Mode {
+	case SimulateOffline:
+		return SimulateActionOffline(before, reserves, prices, simulateArgs)
+	case SimulateDryRun:
+		after, err := c.dryRunAction(ctx, signer, simulateArgs, callOptions)
+		if err != nil {
+			return nil, err
+		}
+		return newSimulationResult(before, after, simulateArgs), nil
+	default:
+		return nil, fmt.Errorf("unknown simulation mode %d", simulateArgs.Mode)
+	}
+}
+
+// SimulateActionOffline apply the action to before and recompute values, health factor and apys.
+// Apys of the reserves are the current ones unless simulateArgs.RateModels is set
+func SimulateActionOffline(before *UserLendingInfo, reserves []ReserveInfo, prices []DolaTokenPrice, simulateArgs SimulateActionArgs) (*SimulationResult, error) {
+	if simulateArgs.Amount == nil || simulateArgs.Amount.Sign() <= 0 {
+		return nil, errors.New("simulate amount must be positive")
+	}
+	if simulateArgs.RateModels != nil {
+		reserves = append([]ReserveInfo{}, reserves...)
+		for i := range reserves {
+			if reserves[i].DolaPoolId != simulateArgs.DolaPoolId {
+				continue
+			}
+			var err error
+			if reserves[i], err = simulateArgs.RateModels.Apply(reserves[i], simulateArgs.CallType, simulateArgs.Amount); err != nil {
+				return nil, err
+			}
+		}
+	}
+	engine := NewRiskEngine(reserves, prices)
+	after := copyLendingInfo(before)
+	amount := simulateArgs.Amount
+	poolId := simulateArgs.DolaPoolId
+
+	switch simulateArgs.CallType {
+	case CallTypeSupply:
+		item := findCollateral(after, poolId)
+		if item == nil {
+			after.CollateralInfos = append(after.CollateralInfos, CollateralItem{DolaPoolId: poolId, CollateralAmount: big.NewInt(0)})
+			item = &after.CollateralInfos[len(after.CollateralInfos)-1]
+		}
+		item.CollateralAmount.Add(item.CollateralAmount, amount)
+	case CallTypeWithdraw:
+		item := findCollateral(after, poolId)
+		if item == nil || item.CollateralAmount.Cmp(amount) < 0 {
+			return nil, fmt.Errorf("withdraw %v more than the collateral of pool %d", amount, poolId)
+		}
+		item.CollateralAmount.Sub(item.CollateralAmount, amount)
+	case CallTypeBorrow:
+		item := findDebt(after, poolId)
+		if item == nil {
+			after.DebtInfos = append(after.DebtInfos, DebtItem{DolaPoolId: poolId, DebtAmount: big.NewInt(0)})
+			item = &after.DebtInfos[len(after.DebtInfos)-1]
+		}
+		item.DebtAmount.Add(item.DebtAmount, amount)
+	case CallTypeRepay:
+		item := findDebt(after, poolId)
+		if item == nil {
+			return nil, fmt.Errorf("no debt in pool %d", poolId)
+		}
+		// the contract keep what exceed the debt as supply, it is not simulated
+		item.DebtAmount.Sub(item.DebtAmount, minBigInt(amount, item.DebtAmount))
+	default:
+		return nil, fmt.Errorf("call type %d can't be simulated", simulateArgs.CallType)
+	}
+	after.CollateralInfos = removeEmptyCollaterals(after.CollateralInfos)
+	after.DebtInfos = removeEmptyDebts(after.DebtInfos)
+
+	if err := engine.refresh(after); err != nil {
+		return nil, err
+	}
+	return newSimulationResult(before, after, simulateArgs), nil
+}
+
+// refresh recompute values, apys and health factor of lendingInfo from its amounts
+func (e *RiskEngine) refresh(lendingInfo *UserLendingInfo) error {
+	report, err := e.Evaluate(lendingInfo)
+	if err != nil {
+		return err
+	}
+	income, cost := big.NewInt(0), big.NewInt(0)
+	for i := range lendingInfo.CollateralInfos {
+		item := &lendingInfo.CollateralInfos[i]
+		if item.CollateralValue, err = e.value(item.DolaPoolId, item.CollateralAmount); err != nil {
+			return err
+		}
+		reserve := e.reserves[item.DolaPoolId]
+		item.SupplyApy, item.BorrowApy = reserve.SupplyApy, reserve.BorrowApy
+		income.Add(income, new(big.Int).Mul(item.CollateralValue, big.NewInt(int64(item.SupplyApy))))
+	}
+	for i := range lendingInfo.DebtInfos {
+		item := &lendingInfo.DebtInfos[i]
+		if item.DebtValue, err = e.value(item.DolaPoolId, item.DebtAmount); err != nil {
+			return err
+		}
+		reserve := e.reserves[item.DolaPoolId]
+		item.SupplyApy, item.BorrowApy = reserve.SupplyApy, reserve.BorrowApy
+		cost.Add(cost, new(big.Int).Mul(item.DebtValue, big.NewInt(int64(item.BorrowApy))))
+	}
+
+	lendingInfo.TotalCollateralValue = report.TotalCollateralValue
+	lendingInfo.TotalDebtValue = report.TotalDebtValue
+	lendingInfo.HealthFactor = report.HealthFactor
+	lendingInfo.TotalSupplyApy = weightedApy(income, report.TotalCollateralValue)
+	lendingInfo.TotalBorrowApy = weightedApy(cost, report.TotalDebtValue)
+	lendingInfo.NetApy = weightedApy(income.Sub(income, cost), report.TotalCollateralValue)
+	return nil
+}
+
+func (c *Contract) dryRunAction(ctx context.Context, signer sui_types.SuiAddress, simulateArgs SimulateActionArgs, callOptions CallOptions) (*UserLendingInfo, error) {
+	if simulateArgs.Amount == nil || simulateArgs.Amount.Sign() <= 0 {
+		return nil, errors.New("simulate amount must be positive")
+	}
+	coinType := firstTypeArg(simulateArgs.TypeArgs)
+	if coinType == "" {
+		return nil, errors.New("dry run need the coin type in TypeArgs")
+	}
+	metadata, err := c.client.GetCoinMetadata(ctx, coinType)
+	if err != nil {
+		return nil, fmt.Errorf("get %s metadata: %w", coinType, err)
+	}
+	// dola amounts have 8 decimals, the contract take coin amounts
+	coinAmount := new(big.Int).Mul(simulateArgs.Amount, pow10(int(metadata.Decimals)))
+	coinAmount.Quo(coinAmount, pow10(dolaDecimal))
+
+	var call moveCall
+	switch simulateArgs.CallType {
+	case CallTypeSupply:
+		call = c.supplyCall(simulateArgs.TypeArgs, SupplyArgs{Pool: simulateArgs.Pool, DepositAmount: coinAmount.String()})
+	case CallTypeWithdraw:
+		call = c.withdrawLocalCall(simulateArgs.TypeArgs, WithdrawArgs{Pool: simulateArgs.Pool, Amount: coinAmount.String()})
+	case CallTypeBorrow:
+		call = c.borrowLocalCall(simulateArgs.TypeArgs, BorrowArgs{Pool: simulateArgs.Pool, Amount: coinAmount.String()})
+	case CallTypeRepay:
+		call = c.repayCall(simulateArgs.TypeArgs, RepayArgs{Pool: simulateArgs.Pool, RepayAmount: coinAmount.String()})
+	default:
+		return nil, fmt.Errorf("call type %d can't be simulated", simulateArgs.CallType)
+	}
+
+	var after *UserLendingInfo
+	qry := c.userLendingInfoQuery(simulateArgs.DolaUserId, &after)
+	tx, err := c.buildCalls(ctx, signer, []moveCall{call, qry.call}, callOptions)
+	if err != nil {
+		return nil, err
+	}
+	effects, err := c.client.DryRunTransaction(ctx, tx.TxBytes)
+	if err != nil {
+		return nil, err
+	}
+	if err = parseLastEvent(effects.Effects.Data, effects.Events, qry.parseEvent); err != nil {
+		return nil, err
+	}
+	return after, nil
+}
+
+func newSimulationResult(before, after *UserLendingInfo, simulateArgs SimulateActionArgs) *SimulationResult {
+	threshold := simulateArgs.HealthThreshold
+	if threshold == nil {
+		threshold = DefaultHealthThreshold
+	}
+	result := &SimulationResult{Before: before, After: after}
+	if after.HealthFactor != nil && after.HealthFactor.Cmp(threshold) < 0 {
+		result.BelowThreshold = true
+		result.Warning = fmt.Sprintf("health factor %s below threshold %s", formatRay(after.HealthFactor), formatRay(threshold))
+	}
+	return result
+}
+
+// weightedApy is sum / total, 0 if total is 0
+func weightedApy(sum, total *big.Int) int {
+	if total.Sign() == 0 {
+		return 0
+	}
+	return int(new(big.Int).Quo(sum, total).Int64())
+}
+
+// formatRay format a 1e27 fixed point number with 4 decimals
+func formatRay(v *big.Int) string {
+	scaled := new(big.Int).Mul(v, big.NewInt(10000))
+	scaled.Quo(scaled, rayUnit)
+	integer, fraction := new(big.Int).QuoRem(scaled, big.NewInt(10000), new(big.Int))
+	return fmt.Sprintf("%s.%04d", integer, fraction.Int64())
+}
+
+func findDebt(lendingInfo *UserLendingInfo, dolaPoolId uint16) *DebtItem {
+	for i := range lendingInfo.DebtInfos {
+		if lendingInfo.DebtInfos[i].DolaPoolId == dolaPoolId {
+			return &lendingInfo.DebtInfos[i]
+		}
+	}
+	return nil
+}
+
+// copyLendingInfo deep copy the amounts of lendingInfo, other big ints are replaced by refresh
+func copyLendingInfo(lendingInfo *UserLendingInfo) *UserLendingInfo {
+	result := *lendingInfo
+	result.CollateralInfos = make([]CollateralItem, len(lendingInfo.CollateralInfos))
+	for i, item := range lendingInfo.CollateralInfos {
+		item.CollateralAmount = new(big.Int).Set(item.CollateralAmount)
+		result.CollateralInfos[i] = item
+	}
+	result.DebtInfos = make([]DebtItem, len(lendingInfo.DebtInfos))
+	for i, item := range lendingInfo.DebtInfos {
+		item.DebtAmount = new(big.Int).Set(item.DebtAmount)
+		result.DebtInfos[i] = item
+	}
+	return &result
+}
+
+func removeEmptyCollaterals(items []CollateralItem) []CollateralItem {
+	result := items[:0]
+	for _, item := range items {
+		if item.CollateralAmount.Sign() > 0 {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+func removeEmptyDebts(items []DebtItem) []DebtItem {
+	result := items[:0]
+	for _, item := range items {
+		if item.DebtAmount.Sign() > 0 {
+			result = append(result, item)
+		}
+	}
+	return result
+}
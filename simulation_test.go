@@ -0,0 +1,46 @@
+package gosuilending
+
+import (
+	"math/big"
+	"testing"
+)
+
+func Test_SimulateActionOffline(t *testing.T) {
+	reserves := []ReserveInfo{
+		{DolaPoolId: 0, CollateralCoefficient: new(big.Int).Quo(new(big.Int).Mul(rayUnit, big.NewInt(8)), big.NewInt(10)), BorrowCoefficient: rayUnit, SupplyApy: 100, BorrowApy: 300},
+		{DolaPoolId: 1, CollateralCoefficient: rayUnit, BorrowCoefficient: rayUnit, SupplyApy: 200, BorrowApy: 500},
+	}
+	prices := []DolaTokenPrice{
+		{DolaPoolId: 0, Decimal: 2, Price: big.NewInt(3000000)}, // 30000
+		{DolaPoolId: 1, Decimal: 2, Price: big.NewInt(100)},     // 1
+	}
+	before := &UserLendingInfo{
+		HealthFactor:    new(big.Int).Set(MaxHealthFactor),
+		CollateralInfos: []CollateralItem{{DolaPoolId: 0, CollateralAmount: big.NewInt(1e8), CollateralValue: big.NewInt(30000e8)}},
+	}
+
+	// borrow 20000$ with 24000$ health collateral value -> 1.2
+	result, err := SimulateActionOffline(before, reserves, prices, SimulateActionArgs{CallType: CallTypeBorrow, DolaPoolId: 1, Amount: big.NewInt(20000e8)})
+	AssertNil(err)
+	hf := new(big.Int).Quo(new(big.Int).Mul(rayUnit, big.NewInt(12)), big.NewInt(10))
+	if result.After.HealthFactor.Cmp(hf) != 0 || result.BelowThreshold {
+		t.Errorf("after = %+v, warning %q", result.After, result.Warning)
+	}
+	// (30000 * 100 - 20000 * 500) / 30000 = -233
+	if result.After.NetApy != -233 || result.After.TotalDebtValue.Cmp(big.NewInt(20000e8)) != 0 {
+		t.Errorf("after = %+v", result.After)
+	}
+	if len(before.DebtInfos) != 0 || before.HealthFactor.Cmp(MaxHealthFactor) != 0 {
+		t.Errorf("before is changed: %+v", before)
+	}
+
+	result, err = SimulateActionOffline(result.After, reserves, prices, SimulateActionArgs{CallType: CallTypeWithdraw, DolaPoolId: 0, Amount: big.NewInt(1e7)})
+	AssertNil(err)
+	if !result.BelowThreshold || result.Warning != "health factor 1.0800 below threshold 1.1000" {
+		t.Errorf("warning = %q", result.Warning)
+	}
+
+	if _, err = SimulateActionOffline(before, reserves, prices, SimulateActionArgs{CallType: CallTypeRepay, DolaPoolId: 1, Amount: big.NewInt(1)}); err == nil {
+		t.Errorf("repay without debt should fail")
+	}
+}
@@ -0,0 +1,359 @@
+package gosuilending
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/coming-chat/go-sui/v2/client"
+	"github.com/coming-chat/go-sui/v2/move_types"
+	"github.com/coming-chat/go-sui/v2/sui_types"
+	"github.com/coming-chat/go-sui/v2/types"
+	"github.com/fardream/go-bcs/bcs"
+)
+
+const getNormalizedMoveFunction = client.SuiMethod("getNormalizedMoveFunction")
+
+// TransactionBuilder compose several lending calls into one programmable transaction,
+// all steps succeed or fail together. e.g. supply collateral then borrow:
+//
+//	tx, err := contract.NewTransactionBuilder().
+//		Supply(collateralType, supplyArgs).
+//		BorrowLocal(debtType, borrowArgs).
+//		Build(ctx, signer, callOptions)
+type TransactionBuilder struct {
+	contract *Contract
+	calls    []moveCall
+}
+
+func (c *Contract) NewTransactionBuilder() *TransactionBuilder {
+	return &TransactionBuilder{contract: c}
+}
+
+func (b *TransactionBuilder) Supply(typeArgs []string, supplyArgs SupplyArgs) *TransactionBuilder {
+	b.calls = append(b.calls, b.contract.supplyCall(typeArgs, supplyArgs))
+	return b
+}
+
+func (b *TransactionBuilder) WithdrawLocal(typeArgs []string, withdrawArgs WithdrawArgs) *TransactionBuilder {
+	b.calls = append(b.calls, b.contract.withdrawLocalCall(typeArgs, withdrawArgs))
+	return b
+}
+
+func (b *TransactionBuilder) WithdrawRemote(typeArgs []string, withdrawArgs WithdrawArgs) *TransactionBuilder {
+	b.calls = append(b.calls, b.contract.withdrawRemoteCall(typeArgs, withdrawArgs))
+	return b
+}
+
+func (b *TransactionBuilder) BorrowLocal(typeArgs []string, borrowArgs BorrowArgs) *TransactionBuilder {
+	b.calls = append(b.calls, b.contract.borrowLocalCall(typeArgs, borrowArgs))
+	return b
+}
+
+func (b *TransactionBuilder) BorrowRemote(typeArgs []string, borrowArgs BorrowArgs) *TransactionBuilder {
+	b.calls = append(b.calls, b.contract.borrowRemoteCall(typeArgs, borrowArgs))
+	return b
+}
+
+func (b *TransactionBuilder) Repay(typeArgs []string, repayArgs RepayArgs) *TransactionBuilder {
+	b.calls = append(b.calls, b.contract.repayCall(typeArgs, repayArgs))
+	return b
+}
+
+func (b *TransactionBuilder) Liquidate(typeArgs []string, liquidateArgs LiquidateArgs) *TransactionBuilder {
+	b.calls = append(b.calls, b.contract.liquidateCall(typeArgs, liquidateArgs))
+	return b
+}
+
+func (b *TransactionBuilder) AsCollateral(collateralArgs CollateralArgs) *TransactionBuilder {
+	b.calls = append(b.calls, b.contract.asCollateralCall(collateralArgs))
+	return b
+}
+
+func (b *TransactionBuilder) CancelAsCollateral(collateralArgs CollateralArgs) *TransactionBuilder {
+	b.calls = append(b.calls, b.contract.cancelAsCollateralCall(collateralArgs))
+	return b
+}
+
+func (b *TransactionBuilder) SendBinding(typeArgs []string, bindingArgs BindingArgs) *TransactionBuilder {
+	b.calls = append(b.calls, b.contract.sendBindingCall(typeArgs, bindingArgs))
+	return b
+}
+
+func (b *TransactionBuilder) SendingUnbinding(typeArgs []string, unbindingArgs UnbindingArgs) *TransactionBuilder {
+	b.calls = append(b.calls, b.contract.sendingUnbindingCall(typeArgs, unbindingArgs))
+	return b
+}
+
+// Build return the unsigned transaction of all steps
+func (b *TransactionBuilder) Build(ctx context.Context, signer sui_types.SuiAddress, callOptions CallOptions) (*types.TransactionBytes, error) {
+	if len(b.calls) == 0 {
+		return nil, errors.New("empty transaction")
+	}
+	return b.contract.buildCalls(ctx, signer, b.calls, callOptions)
+}
+
+func (c *Contract) buildProgrammableTransaction(ctx context.Context, signer sui_types.SuiAddress, pt sui_types.ProgrammableTransaction, callOptions CallOptions) (*types.TransactionBytes, error) {
+	gas, err := c.client.GetObject(ctx, *callOptions.Gas, nil)
+	if err != nil {
+		return nil, err
+	}
+	if gas.Data == nil {
+		return nil, fmt.Errorf("gas object %s not found", callOptions.Gas)
+	}
+	gasRef := sui_types.ObjectRef{ObjectId: *callOptions.Gas, Version: gas.Data.Version.Uint64(), Digest: gas.Data.Digest}
+
+	gasPrice, err := c.client.GetReferenceGasPrice(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tx := sui_types.NewProgrammable(signer, []*sui_types.ObjectRef{&gasRef}, pt, callOptions.GasBudget, gasPrice.Uint64())
+	txBytes, err := bcs.Marshal(tx)
+	if err != nil {
+		return nil, err
+	}
+	return &types.TransactionBytes{
+		Gas:     []sui_types.ObjectRef{gasRef},
+		TxBytes: txBytes,
+	}, nil
+}
+
+// programmableTransaction resolve the json style arguments of calls into programmable transaction inputs
+func (c *Contract) programmableTransaction(ctx context.Context, calls []moveCall) (sui_types.ProgrammableTransaction, error) {
+	ptb := sui_types.NewProgrammableTransactionBuilder()
+	objects, err := c.resolveObjects(ctx, calls)
+	if err != nil {
+		return sui_types.ProgrammableTransaction{}, err
+	}
+	for i, call := range calls {
+		if err := c.appendMoveCall(ctx, ptb, call, objects); err != nil {
+			return sui_types.ProgrammableTransaction{}, fmt.Errorf("step %d %s::%s: %w", i, call.module, call.function, err)
+		}
+	}
+	return ptb.Finish(), nil
+}
+
+func (c *Contract) appendMoveCall(ctx context.Context, ptb *sui_types.ProgrammableTransactionBuilder, call moveCall, objects map[sui_types.ObjectID]sui_types.ObjectArg) error {
+	call = c.resolveCall(call)
+	function, err := c.getMoveFunction(ctx, *call.packageId, call.module, call.function)
+	if err != nil {
+		return err
+	}
+	params := function.Parameters
+	if len(params) > 0 && params[len(params)-1].isTxContext() {
+		params = params[:len(params)-1]
+	}
+	if len(params) != len(call.args) {
+		return fmt.Errorf("expect %d arguments, got %d", len(params), len(call.args))
+	}
+
+	typeArgs := make([]move_types.TypeTag, len(call.typeArgs))
+	for i := range call.typeArgs {
+		if typeArgs[i], err = parseTypeTag(call.typeArgs[i]); err != nil {
+			return err
+		}
+	}
+
+	arguments := make([]sui_types.Argument, len(params))
+	for i := range params {
+		if arguments[i], err = appendArgument(ptb, params[i], typeArgs, call.args[i], objects); err != nil {
+			return fmt.Errorf("argument %d: %w", i, err)
+		}
+	}
+	ptb.Command(sui_types.Command{
+		MoveCall: &sui_types.ProgrammableMoveCall{
+			Package:       *call.packageId,
+			Module:        move_types.Identifier(call.module),
+			Function:      move_types.Identifier(call.function),
+			TypeArguments: typeArgs,
+			Arguments:     arguments,
+		},
+	})
+	return nil
+}
+
+func appendArgument(ptb *sui_types.ProgrammableTransactionBuilder, param moveNormalizedType, typeArgs []move_types.TypeTag, value any, objects map[sui_types.ObjectID]sui_types.ObjectArg) (sui_types.Argument, error) {
+	if split, ok := value.(splitGasCoin); ok {
+		return appendSplitGasCoin(ptb, split.amount)
+	}
+	if param.isObject() {
+		id, ok := objectIdOf(value)
+		if !ok {
+			return sui_types.Argument{}, fmt.Errorf("%T is not an object id", value)
+		}
+		objArg, ok := objects[id]
+		if !ok {
+			return sui_types.Argument{}, fmt.Errorf("object %s not found", id)
+		}
+		if objArg.SharedObject != nil {
+			objArg = newSharedObjectArg(id, objArg.SharedObject.InitialSharedVersion)
+			objArg.SharedObject.Mutable = param.Reference == nil
+		}
+		return ptb.Obj(objArg)
+	}
+
+	if param.Vector != nil && param.Vector.isObject() {
+		ids := objectIdsOf(value)
+		elements := make([]sui_types.Argument, len(ids))
+		for i, id := range ids {
+			objArg, ok := objects[id]
+			if !ok {
+				return sui_types.Argument{}, fmt.Errorf("object %s not found", id)
+			}
+			element, err := ptb.Obj(objArg)
+			if err != nil {
+				return sui_types.Argument{}, err
+			}
+			elements[i] = element
+		}
+		var elementType *move_types.TypeTag
+		if len(ids) == 0 {
+			tag, err := param.Vector.typeTag(typeArgs)
+			if err != nil {
+				return sui_types.Argument{}, err
+			}
+			elementType = &tag
+		}
+		return ptb.Command(sui_types.Command{
+			MakeMoveVec: &struct {
+				TypeTag   *move_types.TypeTag `bcs:"optional"`
+				Arguments []sui_types.Argument
+			}{TypeTag: elementType, Arguments: elements},
+		}), nil
+	}
+
+	pure, err := encodePureArg(param, value)
+	if err != nil {
+		return sui_types.Argument{}, err
+	}
+	return ptb.Input(sui_types.CallArg{Pure: &pure})
+}
+
+// resolveObjects fetch every object used by calls, shared objects are cached by id
+func (c *Contract) resolveObjects(ctx context.Context, calls []moveCall) (map[sui_types.ObjectID]sui_types.ObjectArg, error) {
+	result := make(map[sui_types.ObjectID]sui_types.ObjectArg)
+	seen := make(map[sui_types.ObjectID]bool)
+	var unknown []sui_types.ObjectID
+
+	c.cacheLock.Lock()
+	for _, call := range calls {
+		for _, arg := range call.args {
+			ids := objectIdsOf(arg)
+			if id, ok := objectIdOf(arg); ok {
+				ids = append(ids, id)
+			}
+			for _, id := range ids {
+				if _, ok := result[id]; ok || seen[id] {
+					continue
+				}
+				if version, ok := c.sharedObjects[id]; ok {
+					result[id] = newSharedObjectArg(id, version)
+					continue
+				}
+				seen[id] = true
+				unknown = append(unknown, id)
+			}
+		}
+	}
+	c.cacheLock.Unlock()
+
+	if len(unknown) == 0 {
+		return result, nil
+	}
+	responses, err := c.client.MultiGetObjects(ctx, unknown, &types.SuiObjectDataOptions{ShowOwner: true})
+	if err != nil {
+		return nil, err
+	}
+	if len(responses) != len(unknown) {
+		return nil, errors.New("object response count mismatch")
+	}
+
+	c.cacheLock.Lock()
+	defer c.cacheLock.Unlock()
+	if c.sharedObjects == nil {
+		c.sharedObjects = make(map[sui_types.ObjectID]sui_types.SequenceNumber)
+	}
+	for i, resp := range responses {
+		id := unknown[i]
+		if resp.Data == nil {
+			// maybe an address passed as pure argument, appendArgument report it if an object is expected
+			continue
+		}
+		owner := resp.Data.Owner
+		if owner != nil && owner.ObjectOwnerInternal != nil && owner.Shared != nil && owner.Shared.InitialSharedVersion != nil {
+			c.sharedObjects[id] = *owner.Shared.InitialSharedVersion
+			result[id] = newSharedObjectArg(id, *owner.Shared.InitialSharedVersion)
+			continue
+		}
+		result[id] = sui_types.ObjectArg{
+			ImmOrOwnedObject: &sui_types.ObjectRef{
+				ObjectId: id,
+				Version:  resp.Data.Version.Uint64(),
+				Digest:   resp.Data.Digest,
+			},
+		}
+	}
+	return result, nil
+}
+
+func newSharedObjectArg(id sui_types.ObjectID, initialSharedVersion sui_types.SequenceNumber) sui_types.ObjectArg {
+	return sui_types.ObjectArg{
+		SharedObject: &struct {
+			Id                   sui_types.ObjectID
+			InitialSharedVersion sui_types.SequenceNumber
+			Mutable              bool
+		}{Id: id, InitialSharedVersion: initialSharedVersion, Mutable: true},
+	}
+}
+
+func (c *Contract) getMoveFunction(ctx context.Context, packageId sui_types.ObjectID, module, function string) (*moveNormalizedFunction, error) {
+	key := packageId.String() + "::" + module + "::" + function
+	c.cacheLock.Lock()
+	cached, ok := c.moveFunctions[key]
+	c.cacheLock.Unlock()
+	if ok {
+		return cached, nil
+	}
+
+	var resp moveNormalizedFunction
+	if err := c.client.CallContext(ctx, &resp, getNormalizedMoveFunction, packageId, module, function); err != nil {
+		return nil, err
+	}
+
+	c.cacheLock.Lock()
+	defer c.cacheLock.Unlock()
+	if c.moveFunctions == nil {
+		c.moveFunctions = make(map[string]*moveNormalizedFunction)
+	}
+	c.moveFunctions[key] = &resp
+	return &resp, nil
+}
+
+func objectIdOf(value any) (sui_types.ObjectID, bool) {
+	switch v := value.(type) {
+	case sui_types.ObjectID:
+		return v, true
+	case *sui_types.ObjectID:
+		if v != nil {
+			return *v, true
+		}
+	}
+	return sui_types.ObjectID{}, false
+}
+
+// objectIdsOf return ids of a vector<Object> argument, both []ObjectID and []*ObjectID are used by args
+func objectIdsOf(value any) []sui_types.ObjectID {
+	rv := reflect.ValueOf(value)
+	if rv.Kind() != reflect.Slice {
+		return nil
+	}
+	var ids []sui_types.ObjectID
+	for i := 0; i < rv.Len(); i++ {
+		if id, ok := objectIdOf(rv.Index(i).Interface()); ok {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"math/big"
 	"strconv"
+	"strings"
 
 	"github.com/coming-chat/go-sui/v2/types"
 )
@@ -18,6 +19,14 @@ const (
 	CallTypeUnbinding = 6
 )
 
+// Move struct names of lending events
+const (
+	LocalLendingEventName       = "LocalLendingEvent"
+	LendingPortalEventName      = "LendingPortalEvent"
+	LendingCoreEventName        = "LendingCoreEvent"
+	LendingCoreExecuteEventName = "LendingCoreExecuteEvent"
+)
+
 type (
 	EventHeader struct {
 		Timestamp uint64
@@ -40,6 +49,7 @@ type (
 		Nonce           uint64
 		Sender          string
 		DolaPoolAddress []byte
+		PoolAddress     string // DolaPoolAddress in the format of sui pools, see DefaultChainRegistry
 		Amount          uint64
 		CallType        int
 	}
@@ -50,9 +60,11 @@ type (
 		Nonce           uint64
 		Sender          string
 		DolaPoolAddress []byte
+		PoolAddress     string // DolaPoolAddress in the format of the pools of SourceChainId
 		SourceChainId   uint16
 		DstChainId      uint16
 		Receiver        []byte
+		ReceiverAddress string // Receiver in the format of the users of DstChainId
 		Amount          uint64
 		CallType        int
 	}
@@ -66,6 +78,7 @@ type (
 		DstChainId      uint16
 		DolaPoolId      uint16
 		Receiver        []byte
+		ReceiverAddress string // Receiver in the format of the users of DstChainId
 		Amount          uint64
 		LiquidateUserId uint64
 		CallType        int
@@ -125,6 +138,7 @@ func ParseLendingCoreEvent(event types.SuiEvent) (result *LendingCoreEvent, err
 	result.SourceChainId = uint16(fields["source_chain_id"].(float64))
 	result.DstChainId = uint16(fields["dst_chain_id"].(float64))
 	result.Receiver = parseByteSlice(fields["receiver"].([]interface{}))
+	result.ReceiverAddress = newUserAddressFromBytes(result.DstChainId, result.Receiver)
 	if result.Nonce, err = strconv.ParseUint(fields["nonce"].(string), 10, 64); err != nil {
 		return
 	}
@@ -157,6 +171,7 @@ func ParseLendingPortalEvent(event types.SuiEvent) (result *LendingPortalEvent,
 	result.SourceChainId = uint16(fields["source_chain_id"].(float64))
 	result.DstChainId = uint16(fields["dst_chain_id"].(float64))
 	result.Receiver = parseByteSlice(fields["receiver"].([]interface{}))
+	result.ReceiverAddress = newUserAddressFromBytes(result.DstChainId, result.Receiver)
 	if result.Nonce, err = strconv.ParseUint(fields["nonce"].(string), 10, 64); err != nil {
 		return
 	}
@@ -166,6 +181,7 @@ func ParseLendingPortalEvent(event types.SuiEvent) (result *LendingPortalEvent,
 	result.Sender = fields["sender"].(string)
 	result.CallType = int(fields["call_type"].(float64))
 	result.DolaPoolAddress = parseByteSlice(fields["dola_pool_address"].([]interface{}))
+	result.PoolAddress = newDolaAddressFromBytes(result.SourceChainId, result.DolaPoolAddress)
 	return
 }
 
@@ -189,6 +205,7 @@ func ParseLocalLendingEvent(event types.SuiEvent) (result *LocalLendingEvent, er
 	result.Sender = fields["sender"].(string)
 	result.CallType = int(fields["call_type"].(float64))
 	result.DolaPoolAddress = parseByteSlice(fields["dola_pool_address"].([]interface{}))
+	result.PoolAddress = newDolaAddressFromBytes(DolaChainIdSui, result.DolaPoolAddress)
 	return
 }
 
@@ -214,8 +231,22 @@ func parseMoveEventHeader(event types.SuiEvent) (result MoveEventHeader, err err
 }
 
 func parseEventHeader(event types.SuiEvent) (result EventHeader, err error) {
-	result.Timestamp = event.TimestampMs.Uint64()
+	// events of dry run and dev inspect have no timestamp
+	if event.TimestampMs != nil {
+		result.Timestamp = event.TimestampMs.Uint64()
+	}
 	result.TxDigest = event.Id.TxDigest.String()
 	result.Id = event.Id
 	return
 }
+
+// eventStructName return the struct name of a move event type, 0x1::module::Name<T> -> Name
+func eventStructName(eventType string) string {
+	if i := strings.Index(eventType, "<"); i >= 0 {
+		eventType = eventType[:i]
+	}
+	if i := strings.LastIndex(eventType, "::"); i >= 0 {
+		return eventType[i+len("::"):]
+	}
+	return eventType
+}
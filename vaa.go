@@ -0,0 +1,206 @@
+package gosuilending
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/sha3"
+)
+
+const (
+	vaaVersion         = 1
+	vaaSignatureLength = 65
+)
+
+type (
+	VAASignature struct {
+		GuardianIndex uint8
+		Signature     [vaaSignatureLength]byte
+	}
+
+	// VAA is a wormhole verified action approval, all integers are big endian
+	//
+	//	version u8 | guardian_set_index u32 | len_signatures u8 | signatures ([index u8, sig 65 bytes])
+	//	body: timestamp u32 | nonce u32 | emitter_chain u16 | emitter_address [32]u8 | sequence u64 | consistency_level u8 | payload
+	VAA struct {
+		Version          uint8
+		GuardianSetIndex uint32
+		Signatures       []VAASignature
+
+		Timestamp        time.Time
+		Nonce            uint32
+		EmitterChain     uint16
+		EmitterAddress   [32]byte
+		Sequence         uint64
+		ConsistencyLevel uint8
+		Payload          []byte // message of the emitter, the dola app payload is kept undecoded
+	}
+
+	// VAAFetcher get the signed vaa of a wormhole message, e.g. from a guardian api
+	VAAFetcher interface {
+		FetchVAA(ctx context.Context, emitterChain uint16, emitterAddress [32]byte, sequence uint64) ([]byte, error)
+	}
+
+	// GuardianVAAFetcher fetch vaas from the guardian rest api, GET /v1/signed_vaa/{chain}/{emitter}/{sequence}
+	GuardianVAAFetcher struct {
+		Endpoint string
+		Client   *http.Client // http.DefaultClient if nil
+	}
+
+	// MemoryVAAFetcher serve vaas put by hand, a stand-in of the guardian api for tests and local relayers
+	MemoryVAAFetcher struct {
+		lock sync.RWMutex
+		vaas map[string][]byte
+	}
+)
+
+var ErrVAANotFound = errors.New("vaa not found")
+
+func ParseVAA(data []byte) (*VAA, error) {
+	r := bytes.NewReader(data)
+	v := &VAA{}
+	var signatureCount uint8
+	if err := readBigEndian(r, &v.Version, &v.GuardianSetIndex, &signatureCount); err != nil {
+		return nil, fmt.Errorf("read vaa header: %w", err)
+	}
+	if v.Version != vaaVersion {
+		return nil, fmt.Errorf("unsupported vaa version %d", v.Version)
+	}
+	v.Signatures = make([]VAASignature, signatureCount)
+	for i := range v.Signatures {
+		if err := readBigEndian(r, &v.Signatures[i].GuardianIndex, &v.Signatures[i].Signature); err != nil {
+			return nil, fmt.Errorf("read vaa signature %d: %w", i, err)
+		}
+	}
+
+	var timestamp uint32
+	if err := readBigEndian(r, &timestamp, &v.Nonce, &v.EmitterChain, &v.EmitterAddress, &v.Sequence, &v.ConsistencyLevel); err != nil {
+		return nil, fmt.Errorf("read vaa body: %w", err)
+	}
+	v.Timestamp = time.Unix(int64(timestamp), 0)
+	v.Payload, _ = io.ReadAll(r)
+	return v, nil
+}
+
+func (v *VAA) Marshal() []byte {
+	var buf bytes.Buffer
+	writeBigEndian(&buf, v.Version, v.GuardianSetIndex, uint8(len(v.Signatures)))
+	for _, signature := range v.Signatures {
+		writeBigEndian(&buf, signature.GuardianIndex, signature.Signature)
+	}
+	buf.Write(v.Body())
+	return buf.Bytes()
+}
+
+// Body is the signed part of the vaa
+func (v *VAA) Body() []byte {
+	var buf bytes.Buffer
+	writeBigEndian(&buf, uint32(v.Timestamp.Unix()), v.Nonce, v.EmitterChain, v.EmitterAddress, v.Sequence, v.ConsistencyLevel)
+	buf.Write(v.Payload)
+	return buf.Bytes()
+}
+
+// Digest is keccak256(keccak256(body)), the hash signed by the guardians
+func (v *VAA) Digest() [32]byte {
+	var digest [32]byte
+	copy(digest[:], keccak256(keccak256(v.Body())))
+	return digest
+}
+
+// MessageId is chain/emitter/sequence, the same as the guardian api path
+func (v *VAA) MessageId() string {
+	return vaaMessageId(v.EmitterChain, v.EmitterAddress, v.Sequence)
+}
+
+func vaaMessageId(emitterChain uint16, emitterAddress [32]byte, sequence uint64) string {
+	return fmt.Sprintf("%d/%s/%d", emitterChain, hex.EncodeToString(emitterAddress[:]), sequence)
+}
+
+func (f *GuardianVAAFetcher) FetchVAA(ctx context.Context, emitterChain uint16, emitterAddress [32]byte, sequence uint64) ([]byte, error) {
+	url := strings.TrimSuffix(f.Endpoint, "/") + "/v1/signed_vaa/" + vaaMessageId(emitterChain, emitterAddress, sequence)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	client := f.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrVAANotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch vaa %s: %s", url, resp.Status)
+	}
+	var result struct {
+		VaaBytes string `json:"vaaBytes"`
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("fetch vaa %s: %w", url, err)
+	}
+	return base64.StdEncoding.DecodeString(result.VaaBytes)
+}
+
+func NewMemoryVAAFetcher() *MemoryVAAFetcher {
+	return &MemoryVAAFetcher{vaas: make(map[string][]byte)}
+}
+
+// Put store a vaa by its emitter and sequence
+func (f *MemoryVAAFetcher) Put(vaa []byte) error {
+	v, err := ParseVAA(vaa)
+	if err != nil {
+		return err
+	}
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.vaas[v.MessageId()] = vaa
+	return nil
+}
+
+func (f *MemoryVAAFetcher) FetchVAA(ctx context.Context, emitterChain uint16, emitterAddress [32]byte, sequence uint64) ([]byte, error) {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+	vaa, ok := f.vaas[vaaMessageId(emitterChain, emitterAddress, sequence)]
+	if !ok {
+		return nil, ErrVAANotFound
+	}
+	return vaa, nil
+}
+
+func keccak256(data []byte) []byte {
+	hash := sha3.NewLegacyKeccak256()
+	hash.Write(data)
+	return hash.Sum(nil)
+}
+
+func readBigEndian(r io.Reader, values ...any) error {
+	for _, v := range values {
+		if err := binary.Read(r, binary.BigEndian, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeBigEndian(w io.Writer, values ...any) {
+	for _, v := range values {
+		// writes to a bytes.Buffer never fail
+		_ = binary.Write(w, binary.BigEndian, v)
+	}
+}
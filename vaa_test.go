@@ -0,0 +1,44 @@
+package gosuilending
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+)
+
+func Test_ParseVAA(t *testing.T) {
+	vaa := &VAA{
+		Version:          1,
+		GuardianSetIndex: 3,
+		Signatures:       []VAASignature{{GuardianIndex: 1, Signature: [65]byte{9}}},
+		Timestamp:        time.Unix(1_690_000_000, 0),
+		Nonce:            7,
+		EmitterChain:     21,
+		EmitterAddress:   [32]byte{1},
+		Sequence:         99,
+		ConsistencyLevel: 1,
+		Payload:          []byte{0, 1, 2, 3},
+	}
+	data := vaa.Marshal()
+
+	fetcher := NewMemoryVAAFetcher()
+	AssertNil(fetcher.Put(data))
+	fetched, err := fetcher.FetchVAA(context.Background(), 21, [32]byte{1}, 99)
+	AssertNil(err)
+	parsed, err := ParseVAA(fetched)
+	AssertNil(err)
+	if !bytes.Equal(parsed.Marshal(), data) || parsed.Digest() != vaa.Digest() || !bytes.Equal(parsed.Payload, vaa.Payload) {
+		t.Errorf("vaa not round trip: %+v", parsed)
+	}
+	if parsed.MessageId() != vaaMessageId(21, [32]byte{1}, 99) {
+		t.Errorf("MessageId() = %s", parsed.MessageId())
+	}
+	if _, err = ParseVAA(data[:len(data)-len(vaa.Payload)-1]); err == nil {
+		t.Errorf("ParseVAA() should fail on a truncated body")
+	}
+
+	if _, err = fetcher.FetchVAA(context.Background(), 21, [32]byte{1}, 100); err != ErrVAANotFound {
+		t.Errorf("FetchVAA() error = %v, want ErrVAANotFound", err)
+	}
+}